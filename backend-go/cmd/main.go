@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"os"
+	// Users' digest hours work in any time zone, even on hosts without zoneinfo
+	_ "time/tzdata"
 
 	"task-manager-backend/internal/api"
 	"task-manager-backend/internal/config"
@@ -32,7 +34,10 @@ func main() {
 	}
 
 	// Initialize API server
-	server := api.NewServer(db, cfg)
+	server, err := api.NewServer(db, cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize server:", err)
+	}
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -1,24 +1,60 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"task-manager-backend/internal/config"
 	"task-manager-backend/internal/handlers"
+	"task-manager-backend/internal/jobs"
 	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/notify"
+	"task-manager-backend/internal/realtime"
 	"task-manager-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+const (
+	// webhookBatchSize is how many webhook deliveries are sent per run of the delivery job
+	webhookBatchSize = 100
+	// outboxBatchSize is how many outbox events are relayed at a time
+	outboxBatchSize = 100
+	// reminderBatchSize is how many due reminders are claimed at a time
+	reminderBatchSize = 100
+)
+
 type Server struct {
-	router      *gin.Engine
-	taskHandler *handlers.TaskHandler
-	config      *config.Config
+	router              *gin.Engine
+	taskHandler         *handlers.TaskHandler
+	tagHandler          *handlers.TagHandler
+	projectHandler      *handlers.ProjectHandler
+	commentHandler      *handlers.CommentHandler
+	eventHandler        *handlers.EventHandler
+	viewHandler         *handlers.ViewHandler
+	streamHandler       *handlers.StreamHandler
+	webhookHandler      *handlers.WebhookHandler
+	notificationHandler *handlers.NotificationHandler
+	authHandler         *handlers.AuthHandler
+	authConfig          middleware.AuthConfig
+	config              *config.Config
+	jobs                []scheduledJob
+}
+
+// scheduledJob is background work the server runs while it is up. A job with a wake
+// channel also runs whenever that receives a value.
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	wake     <-chan struct{}
+	job      jobs.Job
 }
 
-func NewServer(db *gorm.DB, cfg *config.Config) *Server {
+func NewServer(db *gorm.DB, cfg *config.Config) (*Server, error) {
 	// Set Gin mode based on environment
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -29,20 +65,146 @@ func NewServer(db *gorm.DB, cfg *config.Config) *Server {
 	// Add middleware
 	router.Use(middleware.CORSMiddleware())
 
+	// Task changes are pushed to connected clients through the event bus
+	bus := realtime.NewBus(cfg.EventsReplaySize, cfg.EventsQueueSize)
+
+	// Task changes are written to the outbox and relayed to its sinks after they commit
+	relay := services.NewOutboxRelay(db, cfg.OutboxMaxAttempts)
+
 	// Initialize services
 	taskService := services.NewTaskService(db)
+	taskService.SetPublisher(bus)
+	taskService.SetOutboxRelay(relay)
+	tagService := services.NewTagService(db, taskService)
+	projectService := services.NewProjectService(db, taskService)
+	commentService := services.NewCommentService(db, taskService)
+	eventService := services.NewEventService(db)
+	viewService := services.NewViewService(db)
+	webhookService := services.NewWebhookService(
+		db,
+		time.Duration(cfg.WebhookTimeoutSeconds)*time.Second,
+		cfg.WebhookMaxAttempts,
+	)
+	webhookService.SetAllowPrivateTargets(cfg.WebhookAllowPrivateTargets)
+	relay.AddSink("webhooks", webhookService)
+	notificationService := services.NewNotificationService(db)
+	relay.AddSink("notifications", notificationService)
+
+	// Due reminders and daily digests go out through the channels each user chose
+	scheduler := services.NewReminderScheduler(db)
+	scheduler.AddNotifier(models.ChannelInApp, notify.NewInAppNotifier(db))
+	scheduler.AddNotifier(models.ChannelWebhook, webhookService)
+	if cfg.SMTPHost != "" {
+		scheduler.AddNotifier(models.ChannelEmail, notify.NewSMTPNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom))
+	}
+	userService := services.NewUserService(db)
+	tokenIssuer := middleware.TokenIssuer{Secret: cfg.JWTSecret, Issuer: cfg.JWTIssuer, Audience: cfg.JWTAudience}
+	tokenService := services.NewTokenService(
+		db,
+		tokenIssuer,
+		time.Duration(cfg.AccessTokenTTLMinutes)*time.Minute,
+		time.Duration(cfg.RefreshTokenTTLHours)*time.Hour,
+	)
 
 	// Initialize handlers
 	taskHandler := handlers.NewTaskHandler(taskService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	projectHandler := handlers.NewProjectHandler(projectService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	eventHandler := handlers.NewEventHandler(eventService)
+	viewHandler := handlers.NewViewHandler(viewService, taskService)
+	streamHandler := handlers.NewStreamHandler(bus, tokenService, tokenIssuer, time.Duration(cfg.EventsHeartbeatSeconds)*time.Second)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	authHandler := handlers.NewAuthHandler(userService, tokenService)
+
+	keys, err := newKeyProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	server := &Server{
-		router:      router,
-		taskHandler: taskHandler,
-		config:      cfg,
+		router:              router,
+		taskHandler:         taskHandler,
+		tagHandler:          tagHandler,
+		projectHandler:      projectHandler,
+		commentHandler:      commentHandler,
+		eventHandler:        eventHandler,
+		viewHandler:         viewHandler,
+		streamHandler:       streamHandler,
+		webhookHandler:      webhookHandler,
+		notificationHandler: notificationHandler,
+		authHandler:         authHandler,
+		authConfig: middleware.AuthConfig{
+			Keys:       keys,
+			Algorithms: cfg.JWTAlgorithms,
+			Issuer:     cfg.JWTIssuer,
+			Audience:   cfg.JWTAudience,
+			Sessions:   tokenService,
+		},
+		config: cfg,
+	}
+
+	if cfg.TrashRetentionDays > 0 && cfg.TrashPurgeIntervalMinutes > 0 {
+		server.jobs = append(server.jobs, scheduledJob{
+			name:     "trash purge",
+			interval: time.Duration(cfg.TrashPurgeIntervalMinutes) * time.Minute,
+			job:      jobs.PurgeTrash(taskService, time.Duration(cfg.TrashRetentionDays)*24*time.Hour),
+		})
+	}
+
+	if cfg.OutboxPollIntervalSeconds > 0 {
+		server.jobs = append(server.jobs, scheduledJob{
+			name:     "outbox relay",
+			interval: time.Duration(cfg.OutboxPollIntervalSeconds) * time.Second,
+			wake:     relay.Wake(),
+			job:      jobs.RelayOutbox(relay, outboxBatchSize, time.Duration(cfg.OutboxRetentionHours)*time.Hour),
+		})
+	}
+
+	if cfg.WebhookDeliveryIntervalSeconds > 0 {
+		server.jobs = append(server.jobs, scheduledJob{
+			name:     "webhook delivery",
+			interval: time.Duration(cfg.WebhookDeliveryIntervalSeconds) * time.Second,
+			job:      jobs.DeliverWebhooks(webhookService, webhookBatchSize),
+		})
+	}
+
+	if cfg.ReminderIntervalSeconds > 0 {
+		server.jobs = append(server.jobs, scheduledJob{
+			name:     "reminders",
+			interval: time.Duration(cfg.ReminderIntervalSeconds) * time.Second,
+			job:      jobs.SendReminders(scheduler, reminderBatchSize),
+		})
+		server.jobs = append(server.jobs, scheduledJob{
+			name:     "overdue notices",
+			interval: time.Duration(cfg.ReminderIntervalSeconds) * time.Second,
+			job:      jobs.NotifyOverdue(notificationService),
+		})
 	}
 
 	server.setupRoutes()
-	return server
+	return server, nil
+}
+
+// newKeyProvider combines the local HMAC secret with any configured public keys
+func newKeyProvider(cfg *config.Config) (middleware.KeyProvider, error) {
+	providers := middleware.ChainKeyProvider{middleware.NewHMACKeyProvider(cfg.JWTSecret)}
+
+	if cfg.JWTPublicKeyFile != "" {
+		pemKeys, err := middleware.LoadPEMKeyFile(cfg.JWTPublicKeyFile, cfg.JWTPublicKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT public key: %w", err)
+		}
+		providers = append(providers, pemKeys)
+	}
+
+	if cfg.JWKSURL != "" {
+		ttl := time.Duration(cfg.JWKSCacheTTLMinutes) * time.Minute
+		providers = append(providers, middleware.NewJWKSKeyProvider(cfg.JWKSURL, ttl))
+	}
+
+	return providers, nil
 }
 
 func (s *Server) setupRoutes() {
@@ -96,26 +258,133 @@ func (s *Server) setupRoutes() {
 		c.JSON(200, gin.H{"message": "Task deleted", "id": id})
 	})
 
+	// Authentication routes
+	auth := v1.Group("/auth")
+	{
+		auth.POST("/register", s.authHandler.Register)
+		auth.POST("/login", s.authHandler.Login)
+		auth.POST("/refresh", s.authHandler.Refresh)
+		auth.POST("/logout", s.authHandler.Logout)
+	}
+
+	// Real-time task changes as Server-Sent Events. EventSource cannot send headers, so
+	// this route also accepts a stream token in the URL.
+	streamTTL := time.Duration(s.config.EventsTokenTTLSeconds) * time.Second
+	v1.GET("/events", middleware.NewStreamAuthMiddleware(s.authConfig, streamTTL), s.streamHandler.Stream)
+
 	// Protected routes (require authentication)
 	protected := v1.Group("/")
-	protected.Use(middleware.AuthMiddleware(s.config.JWTSecret))
+	protected.Use(middleware.NewAuthMiddleware(s.authConfig))
 	{
+		protected.GET("/auth/me", s.authHandler.Me)
+
 		// Task routes
 		tasks := protected.Group("/tasks")
 		{
 			tasks.POST("", s.taskHandler.CreateTask)
 			tasks.GET("", s.taskHandler.GetTasks)
 			tasks.GET("/stats", s.taskHandler.GetTaskStats)
+			tasks.POST("/batch", s.taskHandler.RunBatch)
+			tasks.GET("/trash", s.taskHandler.GetTrash)
+			tasks.GET("/plan", s.taskHandler.GetPlan)
 			tasks.GET("/:id", s.taskHandler.GetTask)
 			tasks.PUT("/:id", s.taskHandler.UpdateTask)
+			tasks.PATCH("/:id", s.taskHandler.PatchTask)
 			tasks.DELETE("/:id", s.taskHandler.DeleteTask)
+			tasks.POST("/:id/restore", s.taskHandler.RestoreTask)
 			tasks.PATCH("/:id/complete", s.taskHandler.MarkTaskAsCompleted)
 			tasks.PATCH("/:id/pending", s.taskHandler.MarkTaskAsPending)
+			tasks.PUT("/:id/assignee", s.taskHandler.AssignTask)
+			tasks.DELETE("/:id/assignee", s.taskHandler.UnassignTask)
+			tasks.GET("/:id/occurrences", s.taskHandler.GetOccurrences)
+			tasks.GET("/:id/dependencies", s.taskHandler.GetDependencies)
+			tasks.POST("/:id/dependencies", s.taskHandler.AddDependency)
+			tasks.DELETE("/:id/dependencies/:blockedById", s.taskHandler.RemoveDependency)
+			tasks.GET("/:id/reminders", s.taskHandler.GetReminders)
+			tasks.POST("/:id/reminders", s.taskHandler.AddReminder)
+			tasks.DELETE("/:id/reminders/:reminderId", s.taskHandler.RemoveReminder)
+			tasks.GET("/:id/subtasks", s.taskHandler.GetSubtasks)
+			tasks.POST("/:id/subtasks", s.taskHandler.CreateSubtask)
+			tasks.GET("/:id/subtasks/:subtaskId", s.taskHandler.GetSubtask)
+			tasks.PUT("/:id/subtasks/:subtaskId", s.taskHandler.UpdateSubtask)
+			tasks.DELETE("/:id/subtasks/:subtaskId", s.taskHandler.DeleteSubtask)
+			tasks.GET("/:id/comments", s.commentHandler.GetComments)
+			tasks.POST("/:id/comments", s.commentHandler.CreateComment)
+			tasks.PUT("/:id/comments/:commentId", s.commentHandler.UpdateComment)
+			tasks.DELETE("/:id/comments/:commentId", s.commentHandler.DeleteComment)
+			tasks.GET("/:id/history", s.eventHandler.GetTaskHistory)
+		}
+
+		// Project routes
+		projects := protected.Group("/projects")
+		{
+			projects.POST("", s.projectHandler.CreateProject)
+			projects.GET("", s.projectHandler.GetProjects)
+			projects.GET("/:id", s.projectHandler.GetProject)
+			projects.PUT("/:id", s.projectHandler.UpdateProject)
+			projects.DELETE("/:id", s.projectHandler.DeleteProject)
+			projects.POST("/:id/members", s.projectHandler.AddMember)
+			projects.PUT("/:id/members/:userId", s.projectHandler.UpdateMember)
+			projects.DELETE("/:id/members/:userId", s.projectHandler.RemoveMember)
+		}
+
+		// Tag routes
+		tags := protected.Group("/tags")
+		{
+			tags.POST("", s.tagHandler.CreateTag)
+			tags.GET("", s.tagHandler.GetTags)
+			tags.PUT("/:id", s.tagHandler.UpdateTag)
+			tags.DELETE("/:id", s.tagHandler.DeleteTag)
+		}
+
+		// Saved and built-in views
+		views := protected.Group("/views")
+		{
+			views.POST("", s.viewHandler.CreateView)
+			views.GET("", s.viewHandler.GetViews)
+			views.GET("/:id", s.viewHandler.GetView)
+			views.PUT("/:id", s.viewHandler.UpdateView)
+			views.DELETE("/:id", s.viewHandler.DeleteView)
+			views.GET("/:id/tasks", s.viewHandler.GetViewTasks)
+		}
+
+		// Activity feed across every visible task
+		protected.GET("/activity", s.eventHandler.GetActivity)
+
+		// Stream tokens for clients that cannot send headers to /events
+		protected.POST("/events/token", s.streamHandler.IssueToken)
+
+		// Webhook routes
+		webhooks := protected.Group("/webhooks")
+		{
+			webhooks.POST("", s.webhookHandler.CreateWebhook)
+			webhooks.GET("", s.webhookHandler.GetWebhooks)
+			webhooks.GET("/:id", s.webhookHandler.GetWebhook)
+			webhooks.PUT("/:id", s.webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", s.webhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", s.webhookHandler.GetDeliveries)
+			webhooks.POST("/:id/deliveries/:deliveryId/retry", s.webhookHandler.RetryDelivery)
+		}
+
+		// Notification routes
+		notifications := protected.Group("/notifications")
+		{
+			notifications.GET("", s.notificationHandler.GetNotifications)
+			notifications.PATCH("/read", s.notificationHandler.MarkAllRead)
+			notifications.PATCH("/:id/read", s.notificationHandler.MarkRead)
+			notifications.GET("/settings", s.notificationHandler.GetSettings)
+			notifications.PUT("/settings", s.notificationHandler.UpdateSettings)
 		}
 	}
 }
 
 func (s *Server) Start(addr string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, scheduled := range s.jobs {
+		go jobs.EveryOrWhen(ctx, scheduled.name, scheduled.interval, scheduled.wake, scheduled.job)
+	}
+
 	log.Printf("Starting server on %s", addr)
 	return s.router.Run(addr)
 }
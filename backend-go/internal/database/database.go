@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/search"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -26,10 +27,74 @@ func Initialize(databaseURL string) (*gorm.DB, error) {
 func Migrate(db *gorm.DB) error {
 	log.Println("Running database migrations...")
 
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		return fmt.Errorf("failed to migrate User model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.RefreshToken{}); err != nil {
+		return fmt.Errorf("failed to migrate RefreshToken model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.Project{}); err != nil {
+		return fmt.Errorf("failed to migrate Project model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.ProjectMember{}); err != nil {
+		return fmt.Errorf("failed to migrate ProjectMember model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.Tag{}); err != nil {
+		return fmt.Errorf("failed to migrate Tag model: %w", err)
+	}
+
 	if err := db.AutoMigrate(&models.Task{}); err != nil {
 		return fmt.Errorf("failed to migrate Task model: %w", err)
 	}
 
+	if err := db.AutoMigrate(&models.Comment{}); err != nil {
+		return fmt.Errorf("failed to migrate Comment model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.TaskEvent{}); err != nil {
+		return fmt.Errorf("failed to migrate TaskEvent model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.TaskDependency{}); err != nil {
+		return fmt.Errorf("failed to migrate TaskDependency model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.SavedView{}); err != nil {
+		return fmt.Errorf("failed to migrate SavedView model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.Webhook{}); err != nil {
+		return fmt.Errorf("failed to migrate Webhook model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.WebhookDelivery{}); err != nil {
+		return fmt.Errorf("failed to migrate WebhookDelivery model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.OutboxEvent{}); err != nil {
+		return fmt.Errorf("failed to migrate OutboxEvent model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.TaskReminder{}); err != nil {
+		return fmt.Errorf("failed to migrate TaskReminder model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.Notification{}); err != nil {
+		return fmt.Errorf("failed to migrate Notification model: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.NotificationSettings{}); err != nil {
+		return fmt.Errorf("failed to migrate NotificationSettings model: %w", err)
+	}
+
+	if err := search.EnsureIndex(db); err != nil {
+		return fmt.Errorf("failed to create task search index: %w", err)
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
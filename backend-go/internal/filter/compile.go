@@ -0,0 +1,170 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Context supplies what a filter is resolved against: the current time (whose location
+// dates and periods are read in) and the user that "me" refers to
+type Context struct {
+	Now    time.Time
+	UserID uint
+}
+
+// Compile translates expr into a parameterized SQL condition on the tasks table. Every
+// condition evaluates to true or false, never NULL, so NOT behaves as expected on tasks
+// without a due date or description.
+func Compile(expr Expr, ctx Context) clause.Expr {
+	c := &compiler{ctx: ctx}
+	c.expr(expr)
+	return clause.Expr{SQL: c.sql.String(), Vars: c.vars}
+}
+
+type compiler struct {
+	ctx  Context
+	sql  strings.Builder
+	vars []interface{}
+}
+
+func (c *compiler) write(sql string, vars ...interface{}) {
+	c.sql.WriteString(sql)
+	c.vars = append(c.vars, vars...)
+}
+
+func (c *compiler) expr(expr Expr) {
+	switch e := expr.(type) {
+	case *And:
+		c.binary(e.Left, " AND ", e.Right)
+	case *Or:
+		c.binary(e.Left, " OR ", e.Right)
+	case *Not:
+		c.write("NOT (")
+		c.expr(e.Operand)
+		c.write(")")
+	case *Condition:
+		c.condition(e)
+	}
+}
+
+func (c *compiler) binary(left Expr, op string, right Expr) {
+	c.write("(")
+	c.expr(left)
+	c.write(op)
+	c.expr(right)
+	c.write(")")
+}
+
+// condition writes a condition; several match values are OR'ed together
+func (c *compiler) condition(e *Condition) {
+	switch e.Field {
+	case FieldStatus:
+		c.write("tasks.status IN ?", texts(e.Values))
+		return
+	case FieldPriority:
+		c.write("tasks.priority IN ?", texts(e.Values))
+		return
+	case FieldTag:
+		// Tags match regardless of case, like titles and descriptions
+		names := texts(e.Values)
+		for i := range names {
+			names[i] = strings.ToLower(strings.TrimSpace(names[i]))
+		}
+		c.write("tasks.id IN (SELECT task_tags.task_id FROM task_tags JOIN tags ON tags.id = task_tags.tag_id WHERE LOWER(tags.name) IN ?)", names)
+		return
+	}
+
+	c.write("(")
+	for i, value := range e.Values {
+		if i > 0 {
+			c.write(" OR ")
+		}
+		switch e.Field {
+		case FieldTitle:
+			c.write(`LOWER(tasks.title) LIKE ? ESCAPE '\'`, containsPattern(value.Text))
+		case FieldDescription:
+			c.write(`LOWER(COALESCE(tasks.description, '')) LIKE ? ESCAPE '\'`, containsPattern(value.Text))
+		case FieldAssignee:
+			c.assignee(value.Text)
+		case FieldDue:
+			c.date("tasks.due_date", true, e.Op, value.Text)
+		case FieldCreated:
+			c.date("tasks.created_at", false, e.Op, value.Text)
+		case FieldUpdated:
+			c.date("tasks.updated_at", false, e.Op, value.Text)
+		}
+	}
+	c.write(")")
+}
+
+func (c *compiler) assignee(value string) {
+	switch value {
+	case "me":
+		c.write("tasks.assignee_id IS NOT NULL AND tasks.assignee_id = ?", c.ctx.UserID)
+	case "none":
+		c.write("tasks.assignee_id IS NULL")
+	default:
+		id, _ := strconv.ParseUint(value, 10, 32)
+		c.write("tasks.assignee_id IS NOT NULL AND tasks.assignee_id = ?", uint(id))
+	}
+}
+
+// date writes a comparison of column against a date value. A date or period is compared as
+// the range [start, end): "<" means before it starts and "<=" before it ends.
+func (c *compiler) date(column string, nullable bool, op Op, value string) {
+	switch value {
+	case DateNone:
+		c.write(column + " IS NULL")
+		return
+	case DateAny:
+		c.write(column + " IS NOT NULL")
+		return
+	case DateOverdue:
+		c.write(column+" IS NOT NULL AND "+column+" < ? AND tasks.status <> ?", c.ctx.Now, "completed")
+		return
+	}
+
+	if nullable {
+		c.write(column + " IS NOT NULL AND ")
+	}
+	start, end, _ := parseDate(value, c.ctx.Now)
+	if start.Equal(end) {
+		switch op {
+		case OpMatch:
+			c.write(column+" = ?", start)
+		default:
+			c.write(column+" "+string(op)+" ?", start)
+		}
+		return
+	}
+	switch op {
+	case OpMatch:
+		c.write(column+" >= ? AND "+column+" < ?", start, end)
+	case OpLess:
+		c.write(column+" < ?", start)
+	case OpLessEqual:
+		c.write(column+" < ?", end)
+	case OpGreater:
+		c.write(column+" >= ?", end)
+	case OpGreaterEqual:
+		c.write(column+" >= ?", start)
+	}
+}
+
+func texts(values []Value) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, value.Text)
+	}
+	return result
+}
+
+// containsPattern builds a case-insensitive LIKE pattern matching value anywhere, escaping
+// LIKE wildcards
+func containsPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(value))
+	return "%" + escaped + "%"
+}
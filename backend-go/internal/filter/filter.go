@@ -0,0 +1,449 @@
+// Package filter parses task filter expressions such as
+//
+//	priority:high AND (due<2026-11-01 OR status:pending)
+//
+// into a typed syntax tree and compiles them to parameterized SQL. Conditions are
+// field:value matches or comparisons; they combine with AND (also implied by juxtaposition),
+// OR and NOT, and group with parentheses. AND binds tighter than OR.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Field is a filterable task attribute
+type Field string
+
+const (
+	FieldStatus      Field = "status"
+	FieldPriority    Field = "priority"
+	FieldDue         Field = "due"
+	FieldCreated     Field = "created"
+	FieldUpdated     Field = "updated"
+	FieldTitle       Field = "title"
+	FieldDescription Field = "description"
+	FieldTag         Field = "tag"
+	FieldAssignee    Field = "assignee"
+)
+
+// Op is a condition operator. OpMatch (":") tests equality, containment for text fields or
+// membership in a date period; the others compare dates.
+type Op string
+
+const (
+	OpMatch        Op = ":"
+	OpLess         Op = "<"
+	OpLessEqual    Op = "<="
+	OpGreater      Op = ">"
+	OpGreaterEqual Op = ">="
+)
+
+// Date keywords. Periods are resolved against the current time when a filter is compiled;
+// weeks start on Monday.
+const (
+	DateNone    = "none"
+	DateAny     = "any"
+	DateOverdue = "overdue"
+)
+
+var periods = []string{"today", "tomorrow", "yesterday", "this-week", "next-week", "last-week", "this-month", "next-month", "last-month"}
+
+// Expr is a node of a filter expression
+type Expr interface {
+	// Pos is the 1-based position of the node in the filter text
+	Pos() int
+}
+
+// And matches tasks matching both sides
+type And struct {
+	Left, Right Expr
+}
+
+// Or matches tasks matching either side
+type Or struct {
+	Left, Right Expr
+}
+
+// Not matches tasks not matching Operand
+type Not struct {
+	Operand Expr
+	At      int
+}
+
+// Condition tests a single field. A match with several comma-separated values matches any
+// of them.
+type Condition struct {
+	Field  Field
+	Op     Op
+	Values []Value
+	At     int
+}
+
+// Value is a literal in a condition
+type Value struct {
+	Text string
+	At   int
+}
+
+func (e *And) Pos() int       { return e.Left.Pos() }
+func (e *Or) Pos() int        { return e.Left.Pos() }
+func (e *Not) Pos() int       { return e.At }
+func (e *Condition) Pos() int { return e.At }
+
+// Error is a parse error at a position in the filter text
+type Error struct {
+	Pos     int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
+
+// fieldSpec describes the operators and values a field accepts
+type fieldSpec struct {
+	ops   []Op
+	check func(value string, op Op) error
+}
+
+var dateOps = []Op{OpMatch, OpLess, OpLessEqual, OpGreater, OpGreaterEqual}
+
+var fields = map[Field]fieldSpec{
+	FieldStatus:      {ops: []Op{OpMatch}, check: oneOf("pending", "completed")},
+	FieldPriority:    {ops: []Op{OpMatch}, check: oneOf("low", "medium", "high")},
+	FieldDue:         {ops: dateOps, check: checkDate(DateNone, DateAny, DateOverdue)},
+	FieldCreated:     {ops: dateOps, check: checkDate()},
+	FieldUpdated:     {ops: dateOps, check: checkDate()},
+	FieldTitle:       {ops: []Op{OpMatch}, check: checkText},
+	FieldDescription: {ops: []Op{OpMatch}, check: checkText},
+	FieldTag:         {ops: []Op{OpMatch}, check: checkText},
+	FieldAssignee:    {ops: []Op{OpMatch}, check: checkAssignee},
+}
+
+var fieldNames = []string{"status", "priority", "due", "created", "updated", "title", "description", "tag", "assignee"}
+
+// Parse parses a filter expression
+func Parse(input string) (Expr, error) {
+	p := &parser{input: input}
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("empty filter")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		if p.peek() == ')' {
+			return nil, p.errorf(`unexpected ")"`)
+		}
+		return nil, p.errorf("expected AND, OR or end of filter")
+	}
+	return expr, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(rune(p.peek())) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) *Error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+func (p *parser) errorAt(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// keyword consumes the given keyword, matched case-insensitively as a whole word
+func (p *parser) keyword(word string) bool {
+	end := p.pos + len(word)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.input) && isWordByte(p.input[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.keyword("OR") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' {
+			return left, nil
+		}
+		start := p.pos
+		if p.keyword("OR") {
+			p.pos = start
+			return left, nil
+		}
+		p.keyword("AND")
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.keyword("NOT") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Operand: operand, At: start + 1}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("expected a condition")
+	}
+	if p.peek() != '(' {
+		return p.parseCondition()
+	}
+
+	open := p.pos
+	p.pos++
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorAt(open, `unclosed "("`)
+	}
+	if p.peek() != ')' {
+		return nil, p.errorf(`expected ")"`)
+	}
+	p.pos++
+	return expr, nil
+}
+
+func (p *parser) parseCondition() (Expr, error) {
+	start := p.pos
+	for !p.eof() && isWordByte(p.peek()) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	if name == "" {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	field := Field(strings.ToLower(name))
+	spec, ok := fields[field]
+	if !ok {
+		return nil, p.errorAt(start, "unknown field %q; expected one of %s", name, strings.Join(fieldNames, ", "))
+	}
+
+	opStart := p.pos
+	op := p.parseOp()
+	if op == "" {
+		return nil, p.errorf(`expected ":", "<", "<=", ">" or ">=" after %q`, name)
+	}
+	if !containsOp(spec.ops, op) {
+		return nil, p.errorAt(opStart, "%s does not support %q", field, op)
+	}
+
+	condition := &Condition{Field: field, Op: op, At: start + 1}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := spec.check(value.Text, op); err != nil {
+			return nil, p.errorAt(value.At-1, "invalid %s value %q: %s", field, value.Text, err)
+		}
+		condition.Values = append(condition.Values, value)
+		if op != OpMatch || p.eof() || p.peek() != ',' {
+			return condition, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseOp() Op {
+	for _, op := range []Op{OpLessEqual, OpGreaterEqual, OpMatch, OpLess, OpGreater} {
+		if strings.HasPrefix(p.input[p.pos:], string(op)) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// parseValue reads a quoted string or a bare word running up to whitespace, ")" or ","
+func (p *parser) parseValue() (Value, error) {
+	start := p.pos
+	if !p.eof() && p.peek() == '"' {
+		quoted := strings.Builder{}
+		for p.pos++; !p.eof(); p.pos++ {
+			switch c := p.peek(); c {
+			case '"':
+				p.pos++
+				return Value{Text: quoted.String(), At: start + 1}, nil
+			case '\\':
+				if p.pos+1 < len(p.input) {
+					p.pos++
+				}
+				quoted.WriteByte(p.peek())
+			default:
+				quoted.WriteByte(c)
+			}
+		}
+		return Value{}, p.errorAt(start, "unterminated string")
+	}
+
+	for !p.eof() && !unicode.IsSpace(rune(p.peek())) && p.peek() != ')' && p.peek() != ',' {
+		p.pos++
+	}
+	if p.pos == start {
+		return Value{}, p.errorf("expected a value")
+	}
+	return Value{Text: p.input[start:p.pos], At: start + 1}, nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func containsOp(ops []Op, op Op) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func oneOf(allowed ...string) func(string, Op) error {
+	return func(value string, _ Op) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+func checkText(value string, _ Op) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("value is empty")
+	}
+	return nil
+}
+
+func checkAssignee(value string, _ Op) error {
+	if value == "me" || value == "none" {
+		return nil
+	}
+	if id, err := strconv.ParseUint(value, 10, 32); err != nil || id == 0 {
+		return fmt.Errorf("expected me, none or a user ID")
+	}
+	return nil
+}
+
+// checkDate accepts dates (2006-01-02), RFC 3339 times, period keywords and the given
+// match-only keywords
+func checkDate(matchOnly ...string) func(string, Op) error {
+	return func(value string, op Op) error {
+		for _, keyword := range matchOnly {
+			if value == keyword {
+				if op != OpMatch {
+					return fmt.Errorf("%s only works with \":\"", keyword)
+				}
+				return nil
+			}
+		}
+		if _, _, ok := parseDate(value, time.Now()); ok {
+			return nil
+		}
+		expected := append([]string{"a date (2006-01-02)", "an RFC 3339 time"}, periods...)
+		return fmt.Errorf("expected %s", strings.Join(append(expected, matchOnly...), ", "))
+	}
+}
+
+// parseDate resolves a date value to the period [start, end) it covers. Times are instants,
+// for which start and end are equal.
+func parseDate(value string, now time.Time) (start, end time.Time, ok bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, t, true
+	}
+	loc := now.Location()
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, t.AddDate(0, 0, 1), true
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	week := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	switch value {
+	case "today":
+		return today, today.AddDate(0, 0, 1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, true
+	case "this-week":
+		return week, week.AddDate(0, 0, 7), true
+	case "next-week":
+		return week.AddDate(0, 0, 7), week.AddDate(0, 0, 14), true
+	case "last-week":
+		return week.AddDate(0, 0, -7), week, true
+	case "this-month":
+		return month, month.AddDate(0, 1, 0), true
+	case "next-month":
+		return month.AddDate(0, 1, 0), month.AddDate(0, 2, 0), true
+	case "last-month":
+		return month.AddDate(0, -1, 0), month, true
+	}
+	return time.Time{}, time.Time{}, false
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssignTask handles PUT /tasks/:id/assignee
+func (h *TaskHandler) AssignTask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	var req models.AssignTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	task, err := h.taskService.AssignTask(userID, taskID, req.AssigneeID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "assignee cannot access task":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee cannot access this task"})
+			return
+		case "version mismatch":
+			versionConflict(c, nil)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign task", "details": err.Error()})
+		return
+	}
+
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+// UnassignTask handles DELETE /tasks/:id/assignee
+func (h *TaskHandler) UnassignTask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	task, err := h.taskService.UnassignTask(userID, taskID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "version mismatch":
+			versionConflict(c, nil)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unassign task", "details": err.Error()})
+		return
+	}
+
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunBatch handles POST /tasks/batch. It responds 207 Multi-Status with one result per
+// operation, each carrying the status code the operation would get as a single request.
+func (h *TaskHandler) RunBatch(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var req models.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+	atomic := req.Mode != models.BatchModeBestEffort
+
+	results := make([]models.BatchResult, len(req.Operations))
+	var valid []int
+	for i := range req.Operations {
+		operation := &req.Operations[i]
+		results[i] = models.BatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+		if message := h.parseBatchOperation(operation); message != "" {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = message
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	// An atomic batch only runs when every operation is valid
+	if atomic && len(valid) < len(req.Operations) {
+		for _, i := range valid {
+			results[i].Status = http.StatusFailedDependency
+			results[i].Error = "Not attempted because another operation is invalid"
+		}
+		c.JSON(http.StatusMultiStatus, gin.H{"results": results, "committed": false})
+		return
+	}
+
+	operations := make([]models.BatchOperation, len(valid))
+	for j, i := range valid {
+		operations[j] = req.Operations[i]
+	}
+	tasks, errs, committed := h.taskService.RunBatch(userID, operations, atomic)
+
+	for j, i := range valid {
+		result := &results[i]
+		switch {
+		case errs[j] != nil && errs[j].Error() == "batch aborted":
+			result.Status = http.StatusFailedDependency
+			result.Error = "Not attempted because an earlier operation failed"
+		case errs[j] != nil && errs[j].Error() == "version mismatch" && operations[j].Version == nil:
+			result.Status = http.StatusConflict
+			result.Error = "Task was modified concurrently; retry the operation"
+		case errs[j] != nil:
+			result.Status, result.Error = taskErrorStatus(errs[j])
+		case !committed:
+			result.Status = http.StatusFailedDependency
+			result.Error = "Rolled back because another operation failed"
+		default:
+			result.Status = http.StatusOK
+			if result.Op == models.BatchCreate {
+				result.Status = http.StatusCreated
+			}
+			if tasks[j] != nil {
+				result.ID = tasks[j].ID
+				result.Task = tasks[j]
+			}
+		}
+	}
+
+	c.JSON(http.StatusMultiStatus, gin.H{"results": results, "committed": committed})
+}
+
+// parseBatchOperation decodes and validates an operation's payload, returning an error
+// message when it is invalid
+func (h *TaskHandler) parseBatchOperation(operation *models.BatchOperation) string {
+	switch operation.Op {
+	case models.BatchCreate:
+		var req models.CreateTaskRequest
+		if message := h.decodeBatchPayload(operation.Task, &req); message != "" {
+			return message
+		}
+		operation.Create = &req
+	case models.BatchUpdate:
+		if operation.ID == 0 {
+			return "Task ID is required"
+		}
+		var req models.UpdateTaskRequest
+		if message := h.decodeBatchPayload(operation.Task, &req); message != "" {
+			return message
+		}
+		operation.Update = &req
+	case models.BatchDelete, models.BatchComplete:
+		if operation.ID == 0 {
+			return "Task ID is required"
+		}
+	default:
+		return "Unsupported operation; use create, update, delete or complete"
+	}
+	return ""
+}
+
+func (h *TaskHandler) decodeBatchPayload(payload json.RawMessage, req interface{}) string {
+	if len(payload) == 0 || bytes.Equal(payload, []byte("null")) {
+		return "Task payload is required"
+	}
+	if err := json.Unmarshal(payload, req); err != nil {
+		return "Invalid task payload: " + err.Error()
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return "Validation failed: " + err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CommentHandler struct {
+	commentService *services.CommentService
+	validator      *validator.Validate
+}
+
+func NewCommentHandler(commentService *services.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		validator:      validator.New(),
+	}
+}
+
+// GetComments handles GET /tasks/:id/comments
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	var filter models.CommentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	if err := h.validator.Struct(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	page, err := h.commentService.GetComments(userID, taskID, &filter)
+	if err != nil {
+		h.handleError(c, err, "Failed to get comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreateComment handles POST /tasks/:id/comments
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	var req models.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(userID, taskID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment handles PUT /tasks/:id/comments/:commentId
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "commentId", "comment ID")
+	if !ok {
+		return
+	}
+
+	var req models.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(userID, taskID, commentID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /tasks/:id/comments/:commentId
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "commentId", "comment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.commentService.DeleteComment(userID, taskID, commentID); err != nil {
+		h.handleError(c, err, "Failed to delete comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func (h *CommentHandler) handleError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "task not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case "comment not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+	case "insufficient permissions":
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only change your own comments"})
+	case "invalid cursor":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDependencies handles GET /tasks/:id/dependencies
+func (h *TaskHandler) GetDependencies(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	deps, err := h.taskService.GetDependencies(userID, taskID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get dependencies", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deps)
+}
+
+// AddDependency handles POST /tasks/:id/dependencies
+func (h *TaskHandler) AddDependency(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	var req models.AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	dependency, err := h.taskService.AddDependency(userID, taskID, req.BlockedByID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "task cannot block itself":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A task cannot block itself"})
+			return
+		case "blocker not found":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Blocking task not found"})
+			return
+		case "dependency already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": "Dependency already exists"})
+			return
+		case "dependency would create a cycle":
+			c.JSON(http.StatusConflict, gin.H{"error": "Dependency would create a cycle"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add dependency", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dependency)
+}
+
+// RemoveDependency handles DELETE /tasks/:id/dependencies/:blockedById
+func (h *TaskHandler) RemoveDependency(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+	blockedByID, ok := parseIDParam(c, "blockedById", "blocking task ID")
+	if !ok {
+		return
+	}
+
+	if err := h.taskService.RemoveDependency(userID, taskID, blockedByID); err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "dependency not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove dependency", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dependency removed successfully"})
+}
+
+// GetPlan handles GET /tasks/plan?ids=1,2,3
+func (h *TaskHandler) GetPlan(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var filter models.PlanFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+	ids, err := filter.TaskIDs()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ids", "details": err.Error()})
+		return
+	}
+
+	plan, err := h.taskService.GetPlan(userID, ids)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get plan", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type EventHandler struct {
+	eventService *services.EventService
+	validator    *validator.Validate
+}
+
+func NewEventHandler(eventService *services.EventService) *EventHandler {
+	return &EventHandler{
+		eventService: eventService,
+		validator:    validator.New(),
+	}
+}
+
+// GetTaskHistory handles GET /tasks/:id/history
+func (h *EventHandler) GetTaskHistory(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	events, err := h.eventService.GetTaskHistory(userID, taskID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task history", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// GetActivity handles GET /activity
+func (h *EventHandler) GetActivity(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var filter models.ActivityFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	// Set default pagination values
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	if err := h.validator.Struct(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": "to must be after from"})
+		return
+	}
+
+	events, total, err := h.eventService.GetActivity(userID, &filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get activity", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+		"pagination": gin.H{
+			"page":  filter.Page,
+			"limit": filter.Limit,
+			"total": total,
+		},
+	})
+}
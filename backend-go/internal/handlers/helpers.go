@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"task-manager-backend/internal/filter"
+	"task-manager-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseIDParam parses a numeric path parameter, writing a 400 response when it is invalid
+func parseIDParam(c *gin.Context, name, label string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + label})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// isRecurrenceError reports whether err was caused by an invalid recurrence setting
+func isRecurrenceError(err error) bool {
+	return strings.HasPrefix(err.Error(), "invalid recurrence rule") || err.Error() == "recurring tasks require a due date"
+}
+
+// taskETag formats a task version as a strong entity tag
+func taskETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// parseIfMatch returns the task version an If-Match header requires, or nil when the
+// header is absent or "*". If-Match compares strongly, so weak tags and tags that are not
+// task versions yield 0, which never matches.
+func parseIfMatch(c *gin.Context) *uint {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+	var version uint
+	if len(header) > 2 && header[0] == '"' && header[len(header)-1] == '"' {
+		if parsed, err := strconv.ParseUint(header[1:len(header)-1], 10, 32); err == nil {
+			version = uint(parsed)
+		}
+	}
+	return &version
+}
+
+// etagListed reports whether an If-None-Match header lists etag, using the weak comparison
+// RFC 9110 prescribes for that header
+func etagListed(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// versionConflict reports a failed version check: 412 when the client sent If-Match, and
+// 409 when another request changed the task while this one was being applied
+func versionConflict(c *gin.Context, expected *uint) {
+	if expected != nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Task has been modified; fetch it again and retry"})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "Task was modified concurrently; retry the request"})
+}
+
+// taskErrorStatus maps a TaskService error to the status code and message the single-task
+// endpoints respond with
+func taskErrorStatus(err error) (int, string) {
+	if isRecurrenceError(err) {
+		return http.StatusBadRequest, "Invalid recurrence: " + err.Error()
+	}
+	switch err.Error() {
+	case "task not found":
+		return http.StatusNotFound, "Task not found"
+	case "project not found":
+		return http.StatusNotFound, "Project not found"
+	case "insufficient permissions":
+		return http.StatusForbidden, "Insufficient permissions"
+	case "subtasks follow their parent's project":
+		return http.StatusBadRequest, "Subtasks cannot be moved to another project"
+	case "version mismatch":
+		return http.StatusPreconditionFailed, "Task has been modified; fetch it again and retry"
+	case "task is blocked":
+		return http.StatusConflict, taskBlockedMessage
+	}
+	return http.StatusInternalServerError, err.Error()
+}
+
+// taskBlockedMessage explains why a blocked task was not completed
+const taskBlockedMessage = "Task is blocked by pending tasks; complete them first or pass force=true"
+
+// paginationLinks builds an RFC 8288 Link header pointing at the pages before and after the
+// current one. The links repeat the request's query with the cursor swapped in.
+func paginationLinks(c *gin.Context, nextCursor, prevCursor string) string {
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{{"next", nextCursor}, {"prev", prevCursor}} {
+		if link.cursor == "" {
+			continue
+		}
+		query := c.Request.URL.Query()
+		query.Del("page")
+		query.Set("cursor", link.cursor)
+		target := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		links = append(links, "<"+target.String()+`>; rel="`+link.rel+`"`)
+	}
+	return strings.Join(links, ", ")
+}
+
+// selectFields reduces each task to the named JSON fields for sparse fieldsets
+func selectFields(tasks []models.Task, fields []string) ([]map[string]json.RawMessage, error) {
+	selected := make([]map[string]json.RawMessage, 0, len(tasks))
+	for i := range tasks {
+		data, err := json.Marshal(&tasks[i])
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		task := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				task[field] = value
+			}
+		}
+		selected = append(selected, task)
+	}
+	return selected, nil
+}
+
+// filterErrorResponse describes an invalid filter expression, including the position of the
+// problem when it is known
+func filterErrorResponse(err error) gin.H {
+	response := gin.H{"error": "Invalid filter", "details": err.Error()}
+	var filterErr *filter.Error
+	if errors.As(err, &filterErr) {
+		response["position"] = filterErr.Pos
+	}
+	return response
+}
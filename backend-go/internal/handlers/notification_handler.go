@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+	validator           *validator.Validate
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		validator:           validator.New(),
+	}
+}
+
+// GetNotifications handles GET /notifications
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var filter models.NotificationFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	// Set default pagination values
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	if err := h.validator.Struct(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	notifications, total, unread, err := h.notificationService.GetNotifications(userID, &filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unreadCount":   unread,
+		"pagination": gin.H{
+			"page":  filter.Page,
+			"limit": filter.Limit,
+			"total": total,
+		},
+	})
+}
+
+// MarkRead handles PATCH /notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	notificationID, ok := parseIDParam(c, "id", "notification ID")
+	if !ok {
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(userID, notificationID)
+	if err != nil {
+		if err.Error() == "notification not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification read", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// MarkAllRead handles PATCH /notifications/read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	marked, err := h.notificationService.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications read", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked read", "marked": marked})
+}
+
+// GetSettings handles GET /notifications/settings
+func (h *NotificationHandler) GetSettings(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	settings, err := h.notificationService.GetSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification settings", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handles PUT /notifications/settings
+func (h *NotificationHandler) UpdateSettings(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var req models.NotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	settings, err := h.notificationService.UpdateSettings(userID, &req)
+	if err != nil {
+		if err.Error() == "invalid timezone" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification settings", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
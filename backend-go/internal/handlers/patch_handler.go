@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/patch"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatchTask handles PATCH /tasks/:id. The body is a JSON Merge Patch or a JSON Patch,
+// chosen by Content-Type, applied to the task's models.TaskDocument.
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	var apply func(doc, patch []byte) ([]byte, error)
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case patch.MergePatchContentType:
+		apply = patch.Merge
+	case patch.JSONPatchContentType:
+		apply = patch.Apply
+	default:
+		c.Header("Accept-Patch", patch.MergePatchContentType+", "+patch.JSONPatchContentType)
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported patch format"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	task, err := h.taskService.GetTaskWithSubtasks(userID, taskID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task", "details": err.Error()})
+		return
+	}
+
+	// Without If-Match the patch still applies to the version it was computed from
+	ifMatch := parseIfMatch(c)
+	if ifMatch != nil && *ifMatch != task.Version {
+		versionConflict(c, ifMatch)
+		return
+	}
+
+	current, err := json.Marshal(models.NewTaskDocument(task))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to patch task", "details": err.Error()})
+		return
+	}
+	patched, err := apply(current, body)
+	if err != nil {
+		if errors.Is(err, patch.ErrTestFailed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Patch test failed", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patch", "details": err.Error()})
+		return
+	}
+
+	var doc models.TaskDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patch", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&doc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	updated, err := h.taskService.ReplaceTask(userID, taskID, &doc, task.Version)
+	if err != nil {
+		if isRecurrenceError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence", "details": err.Error()})
+			return
+		}
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "project not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "subtasks follow their parent's project":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Subtasks cannot be moved to another project"})
+			return
+		case "version mismatch":
+			versionConflict(c, ifMatch)
+			return
+		case "task is blocked":
+			c.JSON(http.StatusConflict, gin.H{"error": taskBlockedMessage})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to patch task", "details": err.Error()})
+		return
+	}
+
+	c.Header("ETag", taskETag(updated.Version))
+	c.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ProjectHandler struct {
+	projectService *services.ProjectService
+	validator      *validator.Validate
+}
+
+func NewProjectHandler(projectService *services.ProjectService) *ProjectHandler {
+	return &ProjectHandler{
+		projectService: projectService,
+		validator:      validator.New(),
+	}
+}
+
+// CreateProject handles POST /projects
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var req models.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	project, err := h.projectService.CreateProject(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// GetProjects handles GET /projects
+func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	projects, err := h.projectService.GetProjects(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get projects", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"projects": projects})
+}
+
+// GetProject handles GET /projects/:id
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	projectID, ok := parseIDParam(c, "id", "project ID")
+	if !ok {
+		return
+	}
+
+	project, err := h.projectService.GetProject(userID, projectID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProject handles PUT /projects/:id
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	projectID, ok := parseIDParam(c, "id", "project ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	project, err := h.projectService.UpdateProject(userID, projectID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update project")
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProject handles DELETE /projects/:id
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	projectID, ok := parseIDParam(c, "id", "project ID")
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.DeleteProject(userID, projectID); err != nil {
+		h.handleError(c, err, "Failed to delete project")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
+
+// AddMember handles POST /projects/:id/members
+func (h *ProjectHandler) AddMember(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	projectID, ok := parseIDParam(c, "id", "project ID")
+	if !ok {
+		return
+	}
+
+	var req models.AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	member, err := h.projectService.AddMember(userID, projectID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to add member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMember handles PUT /projects/:id/members/:userId
+func (h *ProjectHandler) UpdateMember(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	projectID, ok := parseIDParam(c, "id", "project ID")
+	if !ok {
+		return
+	}
+	memberUserID, ok := parseIDParam(c, "userId", "user ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	member, err := h.projectService.UpdateMemberRole(userID, projectID, memberUserID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update member")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember handles DELETE /projects/:id/members/:userId
+func (h *ProjectHandler) RemoveMember(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	projectID, ok := parseIDParam(c, "id", "project ID")
+	if !ok {
+		return
+	}
+	memberUserID, ok := parseIDParam(c, "userId", "user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.RemoveMember(userID, projectID, memberUserID); err != nil {
+		h.handleError(c, err, "Failed to remove member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+func (h *ProjectHandler) handleError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "project not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	case "member not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	case "user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case "insufficient permissions":
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	case "user is already a member":
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+	case "project must keep an owner":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Project must keep at least one owner"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"task-manager-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOccurrences handles GET /tasks/:id/occurrences
+func (h *TaskHandler) GetOccurrences(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+	if err != nil || count < 1 || count > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid count", "details": "count must be between 1 and 100"})
+		return
+	}
+
+	occurrences, err := h.taskService.PreviewOccurrences(userID, taskID, count)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "task is not recurring":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Task is not recurring"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview occurrences", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReminders handles GET /tasks/:id/reminders
+func (h *TaskHandler) GetReminders(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	reminders, err := h.taskService.GetReminders(userID, taskID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reminders", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reminders": reminders})
+}
+
+// AddReminder handles POST /tasks/:id/reminders
+func (h *TaskHandler) AddReminder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	var req models.ReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	reminder, err := h.taskService.AddReminder(userID, taskID, *req.OffsetMinutes)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "reminder already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": "Reminder already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reminder", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// RemoveReminder handles DELETE /tasks/:id/reminders/:reminderId
+func (h *TaskHandler) RemoveReminder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+	reminderID, ok := parseIDParam(c, "reminderId", "reminder ID")
+	if !ok {
+		return
+	}
+
+	if err := h.taskService.RemoveReminder(userID, taskID, reminderID); err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "reminder not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reminder", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reminder removed successfully"})
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// streamWriteTimeout bounds how long a single write to a stream may take before the
+	// client is considered gone
+	streamWriteTimeout = 10 * time.Second
+	// streamRetry is how long a client waits before reconnecting
+	streamRetry = 3 * time.Second
+)
+
+type StreamHandler struct {
+	bus       *realtime.Bus
+	sessions  middleware.SessionChecker
+	tokens    middleware.TokenIssuer
+	heartbeat time.Duration
+}
+
+// NewStreamHandler creates a stream handler that signs stream tokens with tokens. With
+// sessions set, streams whose session is revoked are closed at the next heartbeat.
+func NewStreamHandler(bus *realtime.Bus, sessions middleware.SessionChecker, tokens middleware.TokenIssuer, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &StreamHandler{bus: bus, sessions: sessions, tokens: tokens, heartbeat: heartbeat}
+}
+
+// IssueToken handles POST /events/token. EventSource cannot send an Authorization header,
+// so browsers open the stream with a short-lived stream token in the URL instead. The token
+// carries the session and expiry of the access token it is issued for.
+func (h *StreamHandler) IssueToken(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var expiresAt *time.Time
+	if expiry, ok := middleware.GetTokenExpiryFromContext(c); ok {
+		expiresAt = &expiry
+	}
+	token, err := h.tokens.IssueStreamToken(userID, c.GetString("sessionID"), expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue stream token"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"token": token})
+}
+
+// Stream handles GET /events, a Server-Sent Events stream of changes to the tasks the user
+// can see. A client resumes with the Last-Event-ID header (or the lastEventId query
+// parameter); a "resync" event tells it that some events were lost and it should reload.
+// The stream ends with a "disconnect" event when the access token expires or its session
+// is revoked.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var lastEventID *uint64
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" || c.Query("lastEventId") != "" {
+		if raw == "" {
+			raw = c.Query("lastEventId")
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+		lastEventID = &id
+	}
+
+	sub, replay := h.bus.Subscribe(userID, lastEventID)
+	defer h.bus.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keep proxies such as nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	controller := http.NewResponseController(c.Writer)
+	send := func(write func(w io.Writer)) bool {
+		// Not every writer supports deadlines; such writes simply have none
+		_ = controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		write(c.Writer)
+		return controller.Flush() == nil
+	}
+
+	if !send(func(w io.Writer) {
+		fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+		if !replay.Complete {
+			writeStreamEvent(w, formatEventID(replay.LastID), "resync", []byte("{}"))
+		}
+		for _, event := range replay.Events {
+			writeStreamEvent(w, formatEventID(event.ID), event.Type, event.Data)
+		}
+	}) {
+		return
+	}
+
+	// A nil channel never fires, so tokens without an expiry keep the stream open
+	var expired <-chan time.Time
+	if expiresAt, ok := middleware.GetTokenExpiryFromContext(c); ok {
+		timer := time.NewTimer(time.Until(expiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	sessionID := c.GetString("sessionID")
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-expired:
+			// The client reconnects with a fresh token
+			send(func(w io.Writer) {
+				writeStreamEvent(w, "", "disconnect", []byte(`{"reason":"token expired"}`))
+			})
+			return
+		case <-sub.Dropped():
+			// The client fell too far behind; it reconnects and resumes from its last event
+			send(func(w io.Writer) {
+				writeStreamEvent(w, "", "disconnect", []byte(`{"reason":"slow consumer"}`))
+			})
+			return
+		case event := <-sub.Events():
+			if !send(func(w io.Writer) { writeStreamEvent(w, formatEventID(event.ID), event.Type, event.Data) }) {
+				return
+			}
+		case <-ticker.C:
+			// A failed check is tried again at the next heartbeat
+			if h.sessions != nil && sessionID != "" {
+				if active, err := h.sessions.IsSessionActive(sessionID); err == nil && !active {
+					send(func(w io.Writer) {
+						writeStreamEvent(w, "", "disconnect", []byte(`{"reason":"session revoked"}`))
+					})
+					return
+				}
+			}
+			if !send(func(w io.Writer) { io.WriteString(w, ": heartbeat\n\n") }) {
+				return
+			}
+		}
+	}
+}
+
+// writeStreamEvent writes one Server-Sent Event. Without an id the client keeps resuming from
+// the last event that had one.
+func writeStreamEvent(w io.Writer, id, eventType string, data []byte) {
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+}
+
+func formatEventID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
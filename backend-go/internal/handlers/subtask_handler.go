@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSubtasks handles GET /tasks/:id/subtasks
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	parentID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	subtasks, err := h.taskService.GetSubtasks(userID, parentID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subtasks", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subtasks": subtasks})
+}
+
+// CreateSubtask handles POST /tasks/:id/subtasks
+func (h *TaskHandler) CreateSubtask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	parentID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	var req models.CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	task, err := h.taskService.CreateSubtask(userID, parentID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "subtasks cannot be nested":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Subtasks cannot have subtasks of their own"})
+			return
+		}
+		if isRecurrenceError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence", "details": err.Error()})
+			return
+		}
+		if err.Error() == "insufficient permissions" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subtask", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// GetSubtask handles GET /tasks/:id/subtasks/:subtaskId
+func (h *TaskHandler) GetSubtask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	parentID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+	subtaskID, ok := parseIDParam(c, "subtaskId", "subtask ID")
+	if !ok {
+		return
+	}
+
+	task, err := h.taskService.GetSubtask(userID, parentID, subtaskID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subtask", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UpdateSubtask handles PUT /tasks/:id/subtasks/:subtaskId
+func (h *TaskHandler) UpdateSubtask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	parentID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+	subtaskID, ok := parseIDParam(c, "subtaskId", "subtask ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	if _, err := h.taskService.GetSubtask(userID, parentID, subtaskID); err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subtask", "details": err.Error()})
+		return
+	}
+
+	req.ExpectedVersion = parseIfMatch(c)
+
+	task, err := h.taskService.UpdateTask(userID, subtaskID, &req)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+			return
+		}
+		if isRecurrenceError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence", "details": err.Error()})
+			return
+		}
+		switch err.Error() {
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "subtasks follow their parent's project":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Subtasks cannot be moved to another project"})
+			return
+		case "version mismatch":
+			versionConflict(c, req.ExpectedVersion)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subtask", "details": err.Error()})
+		return
+	}
+
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+// DeleteSubtask handles DELETE /tasks/:id/subtasks/:subtaskId
+func (h *TaskHandler) DeleteSubtask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	parentID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+	subtaskID, ok := parseIDParam(c, "subtaskId", "subtask ID")
+	if !ok {
+		return
+	}
+
+	if _, err := h.taskService.GetSubtask(userID, parentID, subtaskID); err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subtask", "details": err.Error()})
+		return
+	}
+
+	if err := h.taskService.DeleteTask(userID, subtaskID); err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subtask not found"})
+			return
+		}
+		if err.Error() == "insufficient permissions" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete subtask", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Subtask deleted successfully"})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TagHandler struct {
+	tagService *services.TagService
+	validator  *validator.Validate
+}
+
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		validator:  validator.New(),
+	}
+}
+
+// CreateTag handles POST /tags
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var req models.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(userID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// GetTags handles GET /tags
+func (h *TagHandler) GetTags(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	tags, err := h.tagService.GetTags(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// UpdateTag handles PUT /tags/:id
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	tagID, ok := parseIDParam(c, "id", "tag ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(userID, tagID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag handles DELETE /tags/:id
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	tagID, ok := parseIDParam(c, "id", "tag ID")
+	if !ok {
+		return
+	}
+
+	if err := h.tagService.DeleteTag(userID, tagID); err != nil {
+		h.handleError(c, err, "Failed to delete tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+func (h *TagHandler) handleError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "tag not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+	case "tag already exists":
+		c.JSON(http.StatusConflict, gin.H{"error": "Tag already exists"})
+	case "tag name is required":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tag name is required"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
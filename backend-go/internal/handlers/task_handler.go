@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"task-manager-backend/internal/middleware"
 	"task-manager-backend/internal/models"
@@ -45,10 +46,23 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	task, err := h.taskService.CreateTask(userID, &req)
 	if err != nil {
+		switch err.Error() {
+		case "project not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		if isRecurrenceError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence", "details": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task", "details": err.Error()})
 		return
 	}
 
+	c.Header("ETag", taskETag(task.Version))
 	c.JSON(http.StatusCreated, task)
 }
 
@@ -66,35 +80,101 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 		return
 	}
 
-	// Set default pagination values
+	listTasks(c, h.taskService, h.validator, userID, &filter)
+}
+
+// listTasks runs a task listing and writes it with its pagination, applying the default
+// page and limit. It is shared by GET /tasks and saved views.
+func listTasks(c *gin.Context, taskService *services.TaskService, validate *validator.Validate, userID uint, filter *models.TaskFilter) {
 	if filter.Page == 0 {
 		filter.Page = 1
 	}
 	if filter.Limit == 0 {
 		filter.Limit = 10
 	}
+	filter.Q = strings.TrimSpace(filter.Q)
 
-	if err := h.validator.Struct(&filter); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+	if !validateTaskFilter(c, validate, userID, filter) {
+		return
+	}
+	if filter.Cursor != "" && c.Query("page") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": "cursor cannot be combined with page"})
+		return
+	}
+	fields, err := filter.FieldNames()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fields", "details": err.Error()})
 		return
 	}
 
-	tasks, total, err := h.taskService.GetTasksByUser(userID, &filter)
+	page, err := taskService.ListTasks(userID, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks", "details": err.Error()})
+		switch err.Error() {
+		case "invalid cursor":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		case "cursor cannot be combined with search":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks", "details": err.Error()})
+		}
 		return
 	}
 
+	pagination := gin.H{"limit": filter.Limit}
+	if filter.Cursor == "" {
+		pagination["page"] = filter.Page
+	}
+	if page.Total != nil {
+		pagination["total"] = *page.Total
+	}
+	if page.NextCursor != "" {
+		pagination["nextCursor"] = page.NextCursor
+	}
+	if page.PrevCursor != "" {
+		pagination["prevCursor"] = page.PrevCursor
+	}
+	if links := paginationLinks(c, page.NextCursor, page.PrevCursor); links != "" {
+		c.Header("Link", links)
+	}
+
+	var tasks interface{} = page.Tasks
+	if fields != nil {
+		if tasks, err = selectFields(page.Tasks, fields); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tasks", "details": err.Error()})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"tasks": tasks,
-		"pagination": gin.H{
-			"page":  filter.Page,
-			"limit": filter.Limit,
-			"total": total,
-		},
+		"tasks":      tasks,
+		"pagination": pagination,
 	})
 }
 
+// validateTaskFilter checks a task filter's fields, assignee, sort and filter expression,
+// writing a 400 response when one is invalid
+func validateTaskFilter(c *gin.Context, validate *validator.Validate, userID uint, filter *models.TaskFilter) bool {
+	if err := validate.Struct(filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return false
+	}
+	if filter.Assignee != "" {
+		if _, err := filter.AssigneeUserID(userID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return false
+		}
+	}
+	if _, err := filter.SortFields(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort", "details": err.Error()})
+		return false
+	}
+	if _, err := filter.Expression(); err != nil {
+		c.JSON(http.StatusBadRequest, filterErrorResponse(err))
+		return false
+	}
+	return true
+}
+
 // GetTask handles GET /tasks/:id
 func (h *TaskHandler) GetTask(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
@@ -110,7 +190,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.GetTaskByID(userID, uint(taskID))
+	task, err := h.taskService.GetTaskWithSubtasks(userID, uint(taskID))
 	if err != nil {
 		if err.Error() == "task not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
@@ -120,6 +200,12 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
+	etag := taskETag(task.Version)
+	c.Header("ETag", etag)
+	if etagListed(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, task)
 }
 
@@ -149,20 +235,45 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	req.ExpectedVersion = parseIfMatch(c)
+
 	task, err := h.taskService.UpdateTask(userID, uint(taskID), &req)
 	if err != nil {
 		if err.Error() == "task not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 			return
 		}
+		if isRecurrenceError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurrence", "details": err.Error()})
+			return
+		}
+		switch err.Error() {
+		case "project not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "subtasks follow their parent's project":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Subtasks cannot be moved to another project"})
+			return
+		case "version mismatch":
+			versionConflict(c, req.ExpectedVersion)
+			return
+		case "task is blocked":
+			c.JSON(http.StatusConflict, gin.H{"error": taskBlockedMessage})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task", "details": err.Error()})
 		return
 	}
 
+	c.Header("ETag", taskETag(task.Version))
 	c.JSON(http.StatusOK, task)
 }
 
-// DeleteTask handles DELETE /tasks/:id
+// DeleteTask handles DELETE /tasks/:id. The task moves to the trash unless ?permanent=true,
+// which deletes it for good, also from the trash.
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -177,16 +288,29 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	err = h.taskService.DeleteTask(userID, uint(taskID))
+	permanent := c.Query("permanent") == "true"
+	if permanent {
+		err = h.taskService.PurgeTask(userID, uint(taskID))
+	} else {
+		err = h.taskService.DeleteTask(userID, uint(taskID))
+	}
 	if err != nil {
 		if err.Error() == "task not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 			return
 		}
+		if err.Error() == "insufficient permissions" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task", "details": err.Error()})
 		return
 	}
 
+	if permanent {
+		c.JSON(http.StatusOK, gin.H{"message": "Task permanently deleted"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
 
@@ -207,7 +331,8 @@ func (h *TaskHandler) GetTaskStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// MarkTaskAsCompleted handles PATCH /tasks/:id/complete
+// MarkTaskAsCompleted handles PATCH /tasks/:id/complete. A blocked task is only completed
+// with ?force=true.
 func (h *TaskHandler) MarkTaskAsCompleted(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -222,16 +347,30 @@ func (h *TaskHandler) MarkTaskAsCompleted(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.MarkTaskAsCompleted(userID, uint(taskID))
+	force := c.Query("force") == "true"
+	task, err := h.taskService.CompleteTask(userID, uint(taskID), force)
 	if err != nil {
 		if err.Error() == "task not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 			return
 		}
+		if err.Error() == "insufficient permissions" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		if err.Error() == "version mismatch" {
+			versionConflict(c, nil)
+			return
+		}
+		if err.Error() == "task is blocked" {
+			c.JSON(http.StatusConflict, gin.H{"error": taskBlockedMessage})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark task as completed", "details": err.Error()})
 		return
 	}
 
+	c.Header("ETag", taskETag(task.Version))
 	c.JSON(http.StatusOK, task)
 }
 
@@ -256,9 +395,18 @@ func (h *TaskHandler) MarkTaskAsPending(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 			return
 		}
+		if err.Error() == "insufficient permissions" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		if err.Error() == "version mismatch" {
+			versionConflict(c, nil)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark task as pending", "details": err.Error()})
 		return
 	}
 
+	c.Header("ETag", taskETag(task.Version))
 	c.JSON(http.StatusOK, task)
 }
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrash handles GET /tasks/trash
+func (h *TaskHandler) GetTrash(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var filter models.TrashFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	// Set default pagination values
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	if err := h.validator.Struct(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	tasks, total, err := h.taskService.GetTrash(userID, &filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trash", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tasks": tasks,
+		"pagination": gin.H{
+			"page":  filter.Page,
+			"limit": filter.Limit,
+			"total": total,
+		},
+	})
+}
+
+// RestoreTask handles POST /tasks/:id/restore
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	taskID, ok := parseIDParam(c, "id", "task ID")
+	if !ok {
+		return
+	}
+
+	task, err := h.taskService.RestoreTask(userID, taskID)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		case "insufficient permissions":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		case "task is not deleted":
+			c.JSON(http.StatusConflict, gin.H{"error": "Task is not in the trash"})
+			return
+		case "parent task is deleted":
+			c.JSON(http.StatusConflict, gin.H{"error": "Restore the parent task first"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore task", "details": err.Error()})
+		return
+	}
+
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
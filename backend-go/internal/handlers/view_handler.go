@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ViewHandler struct {
+	viewService *services.ViewService
+	taskService *services.TaskService
+	validator   *validator.Validate
+}
+
+func NewViewHandler(viewService *services.ViewService, taskService *services.TaskService) *ViewHandler {
+	return &ViewHandler{
+		viewService: viewService,
+		taskService: taskService,
+		validator:   validator.New(),
+	}
+}
+
+// GetViews handles GET /views
+func (h *ViewHandler) GetViews(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	views, err := h.viewService.GetViews(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get views", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"builtIn": models.BuiltInViews, "views": views})
+}
+
+// CreateView handles POST /views
+func (h *ViewHandler) CreateView(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var req models.SavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+	if !h.validateQuery(c, userID, req.Filter, req.Sort) {
+		return
+	}
+
+	view, err := h.viewService.CreateView(userID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create view")
+		return
+	}
+
+	c.JSON(http.StatusCreated, view)
+}
+
+// GetView handles GET /views/:id, where id is a saved view ID or a built-in view ID
+func (h *ViewHandler) GetView(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	if builtIn, ok := models.FindBuiltInView(c.Param("id")); ok {
+		c.JSON(http.StatusOK, builtIn)
+		return
+	}
+	viewID, ok := parseIDParam(c, "id", "view ID")
+	if !ok {
+		return
+	}
+
+	view, err := h.viewService.GetView(userID, viewID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get view")
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// UpdateView handles PUT /views/:id
+func (h *ViewHandler) UpdateView(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	viewID, ok := h.parseSavedViewID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+	var viewFilter models.ViewFilter
+	if req.Filter != nil {
+		viewFilter = *req.Filter
+	}
+	sort := ""
+	if req.Sort != nil {
+		sort = *req.Sort
+	}
+	if !h.validateQuery(c, userID, viewFilter, sort) {
+		return
+	}
+
+	view, err := h.viewService.UpdateView(userID, viewID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update view")
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteView handles DELETE /views/:id
+func (h *ViewHandler) DeleteView(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	viewID, ok := h.parseSavedViewID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.viewService.DeleteView(userID, viewID); err != nil {
+		h.handleError(c, err, "Failed to delete view")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "View deleted successfully"})
+}
+
+// GetViewTasks handles GET /views/:id/tasks. The view supplies the filters and sort; page,
+// limit, cursor, count and fields come from the query as on GET /tasks.
+func (h *ViewHandler) GetViewTasks(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var filter models.TaskFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	if builtIn, ok := models.FindBuiltInView(c.Param("id")); ok {
+		builtIn.Filter.Apply(&filter, builtIn.Sort)
+	} else {
+		viewID, ok := parseIDParam(c, "id", "view ID")
+		if !ok {
+			return
+		}
+		view, err := h.viewService.GetView(userID, viewID)
+		if err != nil {
+			h.handleError(c, err, "Failed to get view")
+			return
+		}
+		view.Filter.Apply(&filter, view.Sort)
+	}
+
+	listTasks(c, h.taskService, h.validator, userID, &filter)
+}
+
+// parseSavedViewID parses the view ID of a request that changes a view; built-in views
+// cannot be changed
+func (h *ViewHandler) parseSavedViewID(c *gin.Context) (uint, bool) {
+	if _, ok := models.FindBuiltInView(c.Param("id")); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Built-in views cannot be changed"})
+		return 0, false
+	}
+	return parseIDParam(c, "id", "view ID")
+}
+
+// validateQuery checks that a view's filter and sort would be accepted by GET /tasks
+func (h *ViewHandler) validateQuery(c *gin.Context, userID uint, viewFilter models.ViewFilter, sort string) bool {
+	filter := models.TaskFilter{Page: 1, Limit: 1}
+	viewFilter.Apply(&filter, sort)
+	return validateTaskFilter(c, h.validator, userID, &filter)
+}
+
+func (h *ViewHandler) handleError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "view not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+	case "view already exists":
+		c.JSON(http.StatusConflict, gin.H{"error": "View already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
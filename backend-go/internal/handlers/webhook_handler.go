@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	validator      *validator.Validate
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validator.New(),
+	}
+}
+
+// CreateWebhook handles POST /webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(userID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhooks handles GET /webhooks
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// GetWebhook handles GET /webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	webhookID, ok := parseIDParam(c, "id", "webhook ID")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.GetWebhook(userID, webhookID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook handles PUT /webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	webhookID, ok := parseIDParam(c, "id", "webhook ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(userID, webhookID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	webhookID, ok := parseIDParam(c, "id", "webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(userID, webhookID); err != nil {
+		h.handleError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetDeliveries handles GET /webhooks/:id/deliveries
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	webhookID, ok := parseIDParam(c, "id", "webhook ID")
+	if !ok {
+		return
+	}
+
+	var filter models.DeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	// Set default pagination values
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	if err := h.validator.Struct(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+		return
+	}
+
+	deliveries, total, err := h.webhookService.GetDeliveries(userID, webhookID, &filter)
+	if err != nil {
+		h.handleError(c, err, "Failed to get deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"pagination": gin.H{
+			"page":  filter.Page,
+			"limit": filter.Limit,
+			"total": total,
+		},
+	})
+}
+
+// RetryDelivery handles POST /webhooks/:id/deliveries/:deliveryId/retry
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user context"})
+		return
+	}
+
+	webhookID, ok := parseIDParam(c, "id", "webhook ID")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(c, "deliveryId", "delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.RetryDelivery(userID, webhookID, deliveryID)
+	if err != nil {
+		h.handleError(c, err, "Failed to retry delivery")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (h *WebhookHandler) handleError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "webhook not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+	case "delivery not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+	case "webhook URL must resolve to a public address":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook URL must resolve to a public address"})
+	case "delivery is not dead":
+		c.JSON(http.StatusConflict, gin.H{"error": "Only dead deliveries can be retried"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
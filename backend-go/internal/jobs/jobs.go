@@ -0,0 +1,33 @@
+// Package jobs runs background work next to the API server
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a unit of background work. An error is logged and the job runs again at its next tick.
+type Job func(ctx context.Context) error
+
+// Every runs a job immediately and then once per interval until ctx is cancelled
+func Every(ctx context.Context, name string, interval time.Duration, job Job) {
+	EveryOrWhen(ctx, name, interval, nil, job)
+}
+
+// EveryOrWhen runs a job like Every, and also whenever wake receives a value
+func EveryOrWhen(ctx context.Context, name string, interval time.Duration, wake <-chan struct{}, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := job(ctx); err != nil {
+			log.Printf("Job %s failed: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
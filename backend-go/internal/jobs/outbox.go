@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"task-manager-backend/internal/services"
+)
+
+// RelayOutbox hands due outbox events to the relay's sinks, batchSize at a time until none
+// are left, and deletes events processed longer than retention ago
+func RelayOutbox(relay *services.OutboxRelay, batchSize int, retention time.Duration) Job {
+	return func(ctx context.Context) error {
+		for {
+			processed, err := relay.RelayDue(ctx, batchSize)
+			if err != nil {
+				return err
+			}
+			if processed < batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		_, err := relay.PurgeProcessed(time.Now().Add(-retention))
+		return err
+	}
+}
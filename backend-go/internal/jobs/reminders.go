@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"task-manager-backend/internal/services"
+)
+
+// SendReminders sends the task reminders that are due, batchSize at a time until none are
+// left, and then the daily digests whose hour has come
+func SendReminders(scheduler *services.ReminderScheduler, batchSize int) Job {
+	return func(ctx context.Context) error {
+		for {
+			sent, err := scheduler.SendDueReminders(ctx, time.Now(), batchSize)
+			if err != nil {
+				return err
+			}
+			if sent < batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		_, err := scheduler.SendDigests(ctx, time.Now())
+		return err
+	}
+}
+
+// NotifyOverdue adds an inbox notice for each task that has become overdue
+func NotifyOverdue(service *services.NotificationService) Job {
+	return func(ctx context.Context) error {
+		_, err := service.NotifyOverdue(ctx)
+		return err
+	}
+}
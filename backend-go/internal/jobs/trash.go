@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"task-manager-backend/internal/services"
+)
+
+// PurgeTrash permanently deletes tasks that have been in the trash for longer than retention
+func PurgeTrash(taskService *services.TaskService, retention time.Duration) Job {
+	return func(ctx context.Context) error {
+		purged, err := taskService.PurgeTrash(time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.Printf("Purged %d tasks from the trash", purged)
+		}
+		return nil
+	}
+}
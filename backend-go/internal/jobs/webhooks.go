@@ -0,0 +1,15 @@
+package jobs
+
+import (
+	"context"
+
+	"task-manager-backend/internal/services"
+)
+
+// DeliverWebhooks sends queued webhook deliveries that are due, up to batchSize per run
+func DeliverWebhooks(webhookService *services.WebhookService, batchSize int) Job {
+	return func(ctx context.Context) error {
+		_, err := webhookService.DeliverDue(ctx, batchSize)
+		return err
+	}
+}
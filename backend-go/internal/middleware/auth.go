@@ -4,58 +4,260 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// StreamScope is the scope of stream tokens, which only open the event stream
+const StreamScope = "events"
+
 type Claims struct {
-	UserID uint `json:"userId"`
+	UserID    uint   `json:"userId"`
+	SessionID string `json:"sid,omitempty"`
+	// Scope limits where a token is accepted; access tokens have none
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// AuthMiddleware validates JWT tokens and sets user context
+// SessionChecker reports whether the session an access token was issued for is still active
+type SessionChecker interface {
+	IsSessionActive(sessionID string) (bool, error)
+}
+
+// AuthConfig configures how AuthMiddleware verifies tokens
+type AuthConfig struct {
+	// Keys resolves the verification key for each token
+	Keys KeyProvider
+	// Algorithms is the allow-list of accepted `alg` header values; defaults to HS256
+	Algorithms []string
+	// Issuer, when set, must match the token's `iss` claim
+	Issuer string
+	// Audience, when set, must be contained in the token's `aud` claim
+	Audience string
+	// Sessions, when set, rejects tokens whose session has been revoked. Only tokens this
+	// service issued, which are HMAC signed, are checked; the `sid` of an external identity
+	// provider's token names a session the service does not know.
+	Sessions SessionChecker
+}
+
+// TokenIssuer signs the HS256 access tokens handed out by this service
+type TokenIssuer struct {
+	Secret   string
+	Issuer   string
+	Audience string
+}
+
+// Issue signs a token for the given user and session that AuthMiddleware accepts
+func (i TokenIssuer) Issue(userID uint, sessionID string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	tokenString, err := i.sign(userID, sessionID, "", &expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expiresAt, nil
+}
+
+// IssueStreamToken signs a stream token for the given user and session. It opens the event
+// stream shortly after it is issued, and the stream then ends at expiresAt like that of the
+// access token it was issued for. A nil expiresAt keeps the stream open.
+func (i TokenIssuer) IssueStreamToken(userID uint, sessionID string, expiresAt *time.Time) (string, error) {
+	return i.sign(userID, sessionID, StreamScope, expiresAt)
+}
+
+func (i TokenIssuer) sign(userID uint, sessionID, scope string, expiresAt *time.Time) (string, error) {
+	claims := &Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		Scope:     scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  strconv.FormatUint(uint64(userID), 10),
+			Issuer:   i.Issuer,
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if expiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*expiresAt)
+	}
+	if i.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.Audience}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(i.Secret))
+}
+
+// GenerateToken issues a signed HS256 token for the given user and session that AuthMiddleware accepts
+func GenerateToken(jwtSecret string, userID uint, sessionID string, ttl time.Duration) (string, time.Time, error) {
+	return TokenIssuer{Secret: jwtSecret}.Issue(userID, sessionID, ttl)
+}
+
+// AuthMiddleware validates HS256 JWT tokens signed with jwtSecret and sets user context
 func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+	return NewAuthMiddleware(AuthConfig{Keys: NewHMACKeyProvider(jwtSecret)})
+}
+
+// NewAuthMiddleware validates JWT tokens according to cfg and sets user context.
+// Tokens without a `userId` claim fall back to a numeric `sub` claim.
+func NewAuthMiddleware(cfg AuthConfig) gin.HandlerFunc {
+	v := newTokenVerifier(cfg)
+	return v.authenticateHeader
+}
+
+// NewStreamAuthMiddleware authenticates like NewAuthMiddleware, and also accepts a stream
+// token in the `token` query parameter since EventSource clients cannot set headers. A
+// stream token must be used within ttl of being issued and is refused on every other route.
+func NewStreamAuthMiddleware(cfg AuthConfig, ttl time.Duration) gin.HandlerFunc {
+	v := newTokenVerifier(cfg)
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+		tokenString := c.Query("token")
+		if tokenString == "" {
+			v.authenticateHeader(c)
 			return
 		}
-
-		// Check if the header starts with "Bearer "
-		tokenString := ""
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			tokenString = authHeader[7:] // Remove "Bearer " prefix
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		token, claims, ok := v.verify(c, v.streamParser, tokenString, StreamScope)
+		if !ok {
+			return
+		}
+		if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > ttl {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Stream token has expired"})
 			c.Abort()
 			return
 		}
+		v.authorize(c, token, claims)
+	}
+}
+
+// tokenVerifier checks tokens for the auth middlewares
+type tokenVerifier struct {
+	cfg     AuthConfig
+	parser  *jwt.Parser
+	keyFunc jwt.Keyfunc
+	// streamParser accepts only the HS256 stream tokens this service signs
+	streamParser *jwt.Parser
+}
 
-		// Parse and validate the token
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil
-		})
+func newTokenVerifier(cfg AuthConfig) *tokenVerifier {
+	algorithms := cfg.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{jwt.SigningMethodHS256.Alg()}
+	}
+
+	var claimOptions []jwt.ParserOption
+	if cfg.Issuer != "" {
+		claimOptions = append(claimOptions, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		claimOptions = append(claimOptions, jwt.WithAudience(cfg.Audience))
+	}
+
+	parserOptions := append([]jwt.ParserOption{jwt.WithValidMethods(algorithms)}, claimOptions...)
+	streamOptions := append([]jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})}, claimOptions...)
+	return &tokenVerifier{
+		cfg:          cfg,
+		parser:       jwt.NewParser(parserOptions...),
+		streamParser: jwt.NewParser(streamOptions...),
+		keyFunc: func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return cfg.Keys.VerificationKey(kid, token.Method.Alg())
+		},
+	}
+}
+
+// authenticateHeader authenticates the access token in the Authorization header
+func (v *tokenVerifier) authenticateHeader(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		c.Abort()
+		return
+	}
+
+	// Check if the header starts with "Bearer "
+	tokenString := ""
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		tokenString = authHeader[7:] // Remove "Bearer " prefix
+	} else {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		c.Abort()
+		return
+	}
+
+	token, claims, ok := v.verify(c, v.parser, tokenString, "")
+	if !ok {
+		return
+	}
+	v.authorize(c, token, claims)
+}
+
+// verify parses and validates a token, including the alg allow-list, iss, aud and scope,
+// and aborts the request when it is not acceptable
+func (v *tokenVerifier) verify(c *gin.Context, parser *jwt.Parser, tokenString, scope string) (*jwt.Token, *Claims, bool) {
+	token, err := parser.ParseWithClaims(tokenString, &Claims{}, v.keyFunc)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return nil, nil, false
+	}
 
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.Scope != scope {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		c.Abort()
+		return nil, nil, false
+	}
+
+	if claims.UserID == 0 {
+		id, err := strconv.ParseUint(claims.Subject, 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return nil, nil, false
+		}
+		claims.UserID = uint(id)
+	}
+	return token, claims, true
+}
+
+// authorize checks the session of a verified token and sets the user context
+func (v *tokenVerifier) authorize(c *gin.Context, token *jwt.Token, claims *Claims) {
+	// Sessions are only known for the tokens signed with our own secret
+	if !strings.HasPrefix(token.Method.Alg(), "HS") {
+		claims.SessionID = ""
+	}
+	if v.cfg.Sessions != nil && claims.SessionID != "" {
+		active, err := v.cfg.Sessions.IsSessionActive(claims.SessionID)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
 			c.Abort()
 			return
 		}
-
-		claims, ok := token.Claims.(*Claims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
 			c.Abort()
 			return
 		}
+	}
 
-		// Set user ID in context for use in handlers
-		c.Set("userID", claims.UserID)
-		c.Next()
+	// Set user ID in context for use in handlers
+	c.Set("userID", claims.UserID)
+	c.Set("sessionID", claims.SessionID)
+	if claims.ExpiresAt != nil {
+		c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+	}
+	c.Next()
+}
+
+// GetTokenExpiryFromContext returns when the request's access token expires, if it has an
+// `exp` claim
+func GetTokenExpiryFromContext(c *gin.Context) (time.Time, bool) {
+	expiresAt, ok := c.Get("tokenExpiresAt")
+	if !ok {
+		return time.Time{}, false
 	}
+	t, ok := expiresAt.(time.Time)
+	return t, ok
 }
 
 // GetUserIDFromContext extracts user ID from Gin context
@@ -0,0 +1,46 @@
+package models
+
+import "encoding/json"
+
+const (
+	BatchCreate   = "create"
+	BatchUpdate   = "update"
+	BatchDelete   = "delete"
+	BatchComplete = "complete"
+)
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "bestEffort"
+)
+
+// BatchRequest represents the request payload for running several task operations at once.
+// Mode "atomic" (default) runs them in one transaction; "bestEffort" applies each on its own.
+type BatchRequest struct {
+	Mode       string           `json:"mode" validate:"omitempty,oneof=atomic bestEffort"`
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=100"`
+}
+
+// BatchOperation is one operation of a batch. Task holds a CreateTaskRequest for "create"
+// and an UpdateTaskRequest for "update"; ID names the task for every other operation.
+type BatchOperation struct {
+	Op   string          `json:"op"`
+	ID   uint            `json:"id"`
+	Task json.RawMessage `json:"task"`
+	// Version makes an update conditional, like If-Match on PUT /tasks/:id
+	Version *uint `json:"version"`
+
+	Create *CreateTaskRequest `json:"-"`
+	Update *UpdateTaskRequest `json:"-"`
+}
+
+// BatchResult reports the outcome of one operation with the status code it would have
+// received as a single request
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     uint   `json:"id,omitempty"`
+	Status int    `json:"status"`
+	Task   *Task  `json:"task,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a message in a task's discussion thread
+type Comment struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	TaskID    uint           `json:"taskId" gorm:"not null;index"`
+	UserID    uint           `json:"userId" gorm:"not null;index"`
+	Author    *User          `json:"author,omitempty" gorm:"foreignKey:UserID"`
+	Body      string         `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName returns the table name for the Comment model
+func (Comment) TableName() string {
+	return "comments"
+}
+
+// CommentRequest represents the request payload for posting or editing a comment
+type CommentRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=5000"`
+}
+
+// CommentFilter represents pagination options for a comment thread
+type CommentFilter struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" validate:"min=1,max=100"`
+}
+
+// CommentPage is one page of a comment thread, oldest first. NextCursor is empty on the
+// last page.
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskDependency records that a task cannot be completed before another one
+type TaskDependency struct {
+	TaskID      uint      `json:"taskId" gorm:"primaryKey"`
+	BlockedByID uint      `json:"blockedById" gorm:"primaryKey;index"`
+	CreatedByID uint      `json:"createdById" gorm:"not null"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// TableName returns the table name for the TaskDependency model
+func (TaskDependency) TableName() string {
+	return "task_dependencies"
+}
+
+// AddDependencyRequest represents the request payload for adding a blocker to a task
+type AddDependencyRequest struct {
+	BlockedByID uint `json:"blockedById" validate:"required"`
+}
+
+// TaskDependencies lists the visible tasks a task is blocked by and the ones it blocks
+type TaskDependencies struct {
+	BlockedBy []Task `json:"blockedBy"`
+	Blocking  []Task `json:"blocking"`
+}
+
+// PlanFilter selects the tasks to plan
+type PlanFilter struct {
+	// IDs is a comma-separated list of task IDs
+	IDs string `form:"ids" validate:"required,max=1000"`
+}
+
+// maxPlanTasks bounds how many tasks one plan may cover
+const maxPlanTasks = 100
+
+// TaskIDs parses IDs, dropping duplicates
+func (f *PlanFilter) TaskIDs() ([]uint, error) {
+	var ids []uint
+	seen := map[uint]bool{}
+	for _, part := range strings.Split(f.IDs, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil || id == 0 {
+			return nil, errors.New("ids must be a comma-separated list of task IDs")
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	if len(ids) > maxPlanTasks {
+		return nil, errors.New("a plan covers at most 100 tasks")
+	}
+	return ids, nil
+}
+
+// Plan orders tasks by their dependencies. Each stage only depends on earlier stages, so the
+// tasks within a stage can be worked on in parallel.
+type Plan struct {
+	Stages [][]Task `json:"stages"`
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TaskOperation string
+
+const (
+	OperationCreate   TaskOperation = "create"
+	OperationUpdate   TaskOperation = "update"
+	OperationDelete   TaskOperation = "delete"
+	OperationComplete TaskOperation = "complete"
+	OperationReopen   TaskOperation = "reopen"
+	OperationAssign   TaskOperation = "assign"
+	OperationUnassign TaskOperation = "unassign"
+	OperationRestore  TaskOperation = "restore"
+)
+
+// taskEventTypes names the real-time event sent for each operation
+var taskEventTypes = map[TaskOperation]string{
+	OperationCreate:   "task.created",
+	OperationUpdate:   "task.updated",
+	OperationDelete:   "task.deleted",
+	OperationComplete: "task.completed",
+	OperationReopen:   "task.reopened",
+	OperationAssign:   "task.assigned",
+	OperationUnassign: "task.unassigned",
+	OperationRestore:  "task.restored",
+}
+
+// EventType returns the real-time event type for the operation, e.g. "task.completed"
+func (o TaskOperation) EventType() string {
+	if eventType, ok := taskEventTypes[o]; ok {
+		return eventType
+	}
+	return "task." + string(o)
+}
+
+// FieldChange holds a field's value before and after a mutation. Before is null for
+// created tasks and After is null for deleted ones.
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// FieldChanges maps JSON field names to their changes and is stored as a JSON document
+type FieldChanges map[string]FieldChange
+
+// Value implements driver.Valuer
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = FieldChanges{}
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (c *FieldChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = FieldChanges{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into FieldChanges", value)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// TaskEvent is an immutable audit record of a single task mutation
+type TaskEvent struct {
+	ID        uint          `json:"id" gorm:"primaryKey"`
+	TaskID    uint          `json:"taskId" gorm:"not null;index"`
+	ActorID   uint          `json:"actorId" gorm:"not null;index"`
+	Actor     *User         `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	Operation TaskOperation `json:"operation" gorm:"size:20;not null"`
+	Changes   FieldChanges  `json:"changes" gorm:"type:text;not null"`
+	CreatedAt time.Time     `json:"createdAt" gorm:"index"`
+}
+
+// TableName returns the table name for the TaskEvent model
+func (TaskEvent) TableName() string {
+	return "task_events"
+}
+
+// BeforeUpdate keeps recorded events from being changed
+func (e *TaskEvent) BeforeUpdate(tx *gorm.DB) error {
+	return errors.New("task events are immutable")
+}
+
+// BeforeDelete keeps recorded events from being removed
+func (e *TaskEvent) BeforeDelete(tx *gorm.DB) error {
+	return errors.New("task events are immutable")
+}
+
+// TaskChange is the real-time notification of a committed task event. Task holds the task
+// after the change and is omitted for deleted tasks.
+type TaskChange struct {
+	TaskEvent
+	Task *Task `json:"task,omitempty"`
+}
+
+// ActivityFilter represents the time range and pagination options for the activity feed
+type ActivityFilter struct {
+	From  *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To    *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page  int        `form:"page" validate:"min=1"`
+	Limit int        `form:"limit" validate:"min=1,max=100"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Notification channels a user can receive reminders and digests through
+const (
+	ChannelInApp   = "inapp"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// Notification is a message shown to a user inside the app. Key identifies the message so
+// that it is stored once even when it is sent again. ActorID is the user whose change caused
+// it, and is null for reminders, digests and overdue notices.
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"userId" gorm:"not null;index:idx_notifications_inbox,priority:1;uniqueIndex:idx_notifications_key,priority:1"`
+	Key       string     `json:"-" gorm:"size:100;not null;uniqueIndex:idx_notifications_key,priority:2"`
+	Type      string     `json:"type" gorm:"size:50;not null"`
+	Title     string     `json:"title" gorm:"size:300;not null"`
+	Body      string     `json:"body" gorm:"type:text;not null"`
+	TaskID    *uint      `json:"taskId"`
+	ActorID   *uint      `json:"actorId"`
+	ReadAt    *time.Time `json:"readAt" gorm:"index:idx_notifications_inbox,priority:2"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"index"`
+}
+
+// TableName returns the table name for the Notification model
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationSettings holds how a user wants to be notified. Without a row, reminders go
+// to the in-app channel, no digest is sent and every inbox type is on.
+type NotificationSettings struct {
+	UserID        uint       `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	Channels      StringList `json:"channels" gorm:"type:text;not null"`
+	MutedTypes    StringList `json:"mutedTypes" gorm:"type:text;not null;default:'[]'"`
+	DigestEnabled bool       `json:"digestEnabled" gorm:"not null"`
+	DigestHour    int        `json:"digestHour" gorm:"not null"`
+	Timezone      string     `json:"timezone" gorm:"size:64;not null"`
+	LastDigestOn  string     `json:"lastDigestOn" gorm:"size:10;not null;default:''"`
+	LockedUntil   *time.Time `json:"-"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// TableName returns the table name for the NotificationSettings model
+func (NotificationSettings) TableName() string {
+	return "notification_settings"
+}
+
+// DefaultNotificationSettings returns the settings of a user who has not changed them
+func DefaultNotificationSettings(userID uint) NotificationSettings {
+	return NotificationSettings{UserID: userID, Channels: StringList{ChannelInApp}, MutedTypes: StringList{}, DigestHour: 8, Timezone: "UTC"}
+}
+
+// Wants reports whether the user's inbox takes notifications of a type
+func (s *NotificationSettings) Wants(notificationType string) bool {
+	return !s.MutedTypes.Includes(notificationType)
+}
+
+// NotificationSettingsRequest represents the request payload for changing notification
+// settings. The digest is sent once a day at DigestHour in Timezone. MutedTypes, when
+// given, replaces the muted inbox types.
+type NotificationSettingsRequest struct {
+	Channels      []string `json:"channels" validate:"omitempty,dive,oneof=inapp email webhook"`
+	MutedTypes    []string `json:"mutedTypes" validate:"omitempty,dive,oneof=task.created task.updated task.deleted task.completed task.reopened task.assigned task.unassigned task.restored task.overdue"`
+	DigestEnabled *bool    `json:"digestEnabled"`
+	DigestHour    *int     `json:"digestHour" validate:"omitempty,min=0,max=23"`
+	Timezone      *string  `json:"timezone" validate:"omitempty,max=64"`
+}
+
+// NotificationFilter represents the read state filter and pagination options for the inbox
+type NotificationFilter struct {
+	Unread bool `form:"unread"`
+	Page   int  `form:"page" validate:"min=1"`
+	Limit  int  `form:"limit" validate:"min=1,max=100"`
+}
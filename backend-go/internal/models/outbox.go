@@ -0,0 +1,80 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// UserIDs is a list of user IDs stored as a JSON array
+type UserIDs []uint
+
+// Value implements driver.Valuer
+func (ids UserIDs) Value() (driver.Value, error) {
+	if ids == nil {
+		ids = UserIDs{}
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (ids *UserIDs) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*ids = UserIDs{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, ids)
+	case string:
+		return json.Unmarshal([]byte(v), ids)
+	default:
+		return fmt.Errorf("cannot scan %T into UserIDs", value)
+	}
+}
+
+// Includes reports whether the list contains a user
+func (ids UserIDs) Includes(userID uint) bool {
+	for _, id := range ids {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// EventPayload is the message the outbox hands to its sinks, and the body posted to
+// webhooks. EventID identifies the task event and stays the same across retries.
+type EventPayload struct {
+	EventID    uint       `json:"eventId"`
+	Type       string     `json:"type"`
+	OccurredAt time.Time  `json:"occurredAt"`
+	Data       TaskChange `json:"data"`
+}
+
+// OutboxEvent is a task event written in the same transaction as the change it records and
+// relayed to the outbox sinks after it commits. Audience lists the users who could see the
+// task at the time. DeadAt is set once the last attempt failed; the event is then kept but
+// no longer relayed.
+type OutboxEvent struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	IdempotencyKey string       `json:"idempotencyKey" gorm:"size:100;not null;uniqueIndex"`
+	Type           string       `json:"type" gorm:"size:50;not null"`
+	Payload        JSONDocument `json:"payload" gorm:"type:text;not null"`
+	Audience       UserIDs      `json:"audience" gorm:"type:text;not null"`
+	Attempts       int          `json:"attempts" gorm:"not null"`
+	NextAttemptAt  time.Time    `json:"nextAttemptAt" gorm:"not null;index:idx_outbox_events_due,priority:2"`
+	ProcessedAt    *time.Time   `json:"processedAt" gorm:"index:idx_outbox_events_due,priority:1"`
+	LastError      string       `json:"lastError" gorm:"size:1000;not null;default:''"`
+	DeadAt         *time.Time   `json:"deadAt" gorm:"index"`
+	CreatedAt      time.Time    `json:"createdAt"`
+}
+
+// TableName returns the table name for the OutboxEvent model
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProjectRole string
+
+const (
+	RoleOwner  ProjectRole = "owner"
+	RoleEditor ProjectRole = "editor"
+	RoleViewer ProjectRole = "viewer"
+)
+
+// CanEdit reports whether the role may create, change and delete tasks
+func (r ProjectRole) CanEdit() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// CanManage reports whether the role may change the project and its members
+func (r ProjectRole) CanManage() bool {
+	return r == RoleOwner
+}
+
+// Project is a shared workspace. Its tasks are visible to every member and editable
+// by owners and editors.
+type Project struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	Name        string          `json:"name" gorm:"size:100;not null"`
+	Description *string         `json:"description" gorm:"type:text"`
+	OwnerID     uint            `json:"ownerId" gorm:"not null;index"`
+	Members     []ProjectMember `json:"members,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt  `json:"-" gorm:"index"`
+}
+
+// TableName returns the table name for the Project model
+func (Project) TableName() string {
+	return "projects"
+}
+
+// ProjectMember grants a user a role in a project
+type ProjectMember struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	ProjectID uint        `json:"projectId" gorm:"not null;uniqueIndex:idx_project_members_project_user"`
+	UserID    uint        `json:"userId" gorm:"not null;uniqueIndex:idx_project_members_project_user;index"`
+	Role      ProjectRole `json:"role" gorm:"size:20;not null"`
+	User      *User       `json:"user,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// TableName returns the table name for the ProjectMember model
+func (ProjectMember) TableName() string {
+	return "project_members"
+}
+
+// CreateProjectRequest represents the request payload for creating a project
+type CreateProjectRequest struct {
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	Description *string `json:"description"`
+}
+
+// UpdateProjectRequest represents the request payload for updating a project
+type UpdateProjectRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description"`
+}
+
+// AddMemberRequest represents the request payload for adding a user to a project
+type AddMemberRequest struct {
+	Email string      `json:"email" validate:"required,email"`
+	Role  ProjectRole `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// UpdateMemberRequest represents the request payload for changing a member's role
+type UpdateMemberRequest struct {
+	Role ProjectRole `json:"role" validate:"required,oneof=owner editor viewer"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// RefreshToken is a single-use token that can be exchanged for a new access token.
+// Every token issued from the same login shares a FamilyID, which doubles as the
+// session ID embedded in access tokens.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"userId" gorm:"not null;index"`
+	FamilyID  string     `json:"familyId" gorm:"size:64;not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"not null"`
+	UsedAt    *time.Time `json:"usedAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TableName returns the table name for the RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsExpired checks if the refresh token has expired
+func (r *RefreshToken) IsExpired() bool {
+	return r.ExpiresAt.Before(time.Now())
+}
+
+// IsUsed checks if the refresh token has already been exchanged
+func (r *RefreshToken) IsUsed() bool {
+	return r.UsedAt != nil
+}
+
+// IsRevoked checks if the refresh token has been revoked
+func (r *RefreshToken) IsRevoked() bool {
+	return r.RevokedAt != nil
+}
+
+// RefreshRequest represents the request payload for refreshing or revoking a session
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
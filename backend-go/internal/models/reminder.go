@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// TaskReminder asks for a user to be reminded a number of minutes before a task is due.
+// RemindAt follows the task's due date and is null while the task has none.
+type TaskReminder struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	TaskID        uint       `json:"taskId" gorm:"not null;uniqueIndex:idx_task_reminders_offset,priority:1"`
+	UserID        uint       `json:"userId" gorm:"not null;uniqueIndex:idx_task_reminders_offset,priority:2"`
+	OffsetMinutes int        `json:"offsetMinutes" gorm:"not null;uniqueIndex:idx_task_reminders_offset,priority:3"`
+	RemindAt      *time.Time `json:"remindAt" gorm:"index"`
+	SentAt        *time.Time `json:"sentAt"`
+	LockedUntil   *time.Time `json:"-"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// TableName returns the table name for the TaskReminder model
+func (TaskReminder) TableName() string {
+	return "task_reminders"
+}
+
+// Schedule sets RemindAt from the task's due date
+func (r *TaskReminder) Schedule(dueDate *time.Time) {
+	if dueDate == nil {
+		r.RemindAt = nil
+		return
+	}
+	remindAt := dueDate.Add(-time.Duration(r.OffsetMinutes) * time.Minute)
+	r.RemindAt = &remindAt
+}
+
+// ReminderRequest represents the request payload for adding a reminder. An offset of 0
+// reminds at the due time; the longest offset is a year.
+type ReminderRequest struct {
+	OffsetMinutes *int `json:"offsetMinutes" validate:"required,min=0,max=525600"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultTagColor is used when a tag is created without a color
+const DefaultTagColor = "#6b7280"
+
+// Tag is a user-defined label that can be attached to any number of tasks
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:50;not null;uniqueIndex:idx_tags_user_name"`
+	Color     string    `json:"color" gorm:"size:7;not null"`
+	UserID    uint      `json:"userId" gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName returns the table name for the Tag model
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// NormalizeTagName trims surrounding whitespace from a tag name and lower-cases it, so that
+// names differing only in case are the same tag
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// CreateTagRequest represents the request payload for creating a tag
+type CreateTagRequest struct {
+	Name  string  `json:"name" validate:"required,min=1,max=50"`
+	Color *string `json:"color" validate:"omitempty,hexcolor"`
+}
+
+// UpdateTagRequest represents the request payload for renaming or recoloring a tag
+type UpdateTagRequest struct {
+	Name  *string `json:"name" validate:"omitempty,min=1,max=50"`
+	Color *string `json:"color" validate:"omitempty,hexcolor"`
+}
+
+const (
+	TagModeAny = "any"
+	TagModeAll = "all"
+)
+
+// TagStats represents task counts for a single tag
+type TagStats struct {
+	TagID     uint   `json:"tagId"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Total     int64  `json:"total"`
+	Pending   int64  `json:"pending"`
+	Completed int64  `json:"completed"`
+	Overdue   int64  `json:"overdue"`
+}
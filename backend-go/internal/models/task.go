@@ -1,8 +1,15 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
+	"task-manager-backend/internal/filter"
+
 	"gorm.io/gorm"
 )
 
@@ -21,16 +28,35 @@ const (
 )
 
 type Task struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Title       string         `json:"title" gorm:"not null" validate:"required,min=1,max=200"`
-	Description *string        `json:"description" gorm:"type:text"`
-	Status      TaskStatus     `json:"status" gorm:"default:'pending'" validate:"oneof=pending completed"`
-	Priority    TaskPriority   `json:"priority" gorm:"default:'medium'" validate:"oneof=low medium high"`
-	DueDate     *time.Time     `json:"dueDate"`
-	UserID      uint           `json:"userId" gorm:"not null" validate:"required"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Title            string         `json:"title" gorm:"not null" validate:"required,min=1,max=200"`
+	Description      *string        `json:"description" gorm:"type:text"`
+	Status           TaskStatus     `json:"status" gorm:"default:'pending'" validate:"oneof=pending completed"`
+	Priority         TaskPriority   `json:"priority" gorm:"default:'medium'" validate:"oneof=low medium high"`
+	DueDate          *time.Time     `json:"dueDate"`
+	UserID           uint           `json:"userId" gorm:"not null" validate:"required"`
+	ProjectID        *uint          `json:"projectId" gorm:"index"`
+	AssigneeID       *uint          `json:"assigneeId" gorm:"index"`
+	AssignedByID     *uint          `json:"assignedById"`
+	AssignedAt       *time.Time     `json:"assignedAt"`
+	CommentCount     int            `json:"commentCount" gorm:"->;not null;default:0"`
+	ParentID         *uint          `json:"parentId" gorm:"index"`
+	AutoComplete     bool           `json:"autoComplete" gorm:"not null;default:false"`
+	Position         int            `json:"position" gorm:"not null;default:0"`
+	IsBlocked        bool           `json:"isBlocked" gorm:"-"`
+	Subtasks         []Task         `json:"subtasks,omitempty" gorm:"foreignKey:ParentID"`
+	Tags             []Tag          `json:"tags,omitempty" gorm:"many2many:task_tags"`
+	Recurrence       *string        `json:"recurrence" gorm:"size:255"`
+	RecurrenceStart  *time.Time     `json:"recurrenceStart,omitempty"`
+	RecurrenceIndex  int            `json:"recurrenceIndex,omitempty" gorm:"not null;default:0"`
+	NextOccurrenceID *uint          `json:"nextOccurrenceId,omitempty"`
+	OverdueNoticeFor *time.Time     `json:"-"`
+	Version          uint           `json:"version" gorm:"not null;default:1"`
+	SearchRank       *float64       `json:"searchRank,omitempty" gorm:"->;-:migration"`
+	Snippet          *string        `json:"snippet,omitempty" gorm:"->;-:migration"`
+	CreatedAt        time.Time      `json:"createdAt"`
+	UpdatedAt        time.Time      `json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // TableName returns the table name for the Task model
@@ -51,6 +77,16 @@ func (t *Task) IsCompleted() bool {
 	return t.Status == StatusCompleted
 }
 
+// IsSubtask checks if the task belongs to a parent task
+func (t *Task) IsSubtask() bool {
+	return t.ParentID != nil
+}
+
+// IsRecurring checks if the task repeats on a schedule
+func (t *Task) IsRecurring() bool {
+	return t.Recurrence != nil && *t.Recurrence != ""
+}
+
 // MarkAsCompleted marks the task as completed
 func (t *Task) MarkAsCompleted() {
 	t.Status = StatusCompleted
@@ -75,30 +111,147 @@ func (t *Task) GetPriorityWeight() int {
 	}
 }
 
+// SubtaskProgress returns the number of completed subtasks and the total number of subtasks
+func (t *Task) SubtaskProgress() (completed, total int) {
+	for i := range t.Subtasks {
+		if t.Subtasks[i].IsCompleted() {
+			completed++
+		}
+	}
+	return completed, len(t.Subtasks)
+}
+
 // CreateTaskRequest represents the request payload for creating a task
 type CreateTaskRequest struct {
-	Title       string        `json:"title" validate:"required,min=1,max=200"`
-	Description *string       `json:"description"`
-	Priority    *TaskPriority `json:"priority" validate:"omitempty,oneof=low medium high"`
-	DueDate     *time.Time    `json:"dueDate"`
+	Title        string        `json:"title" validate:"required,min=1,max=200"`
+	Description  *string       `json:"description"`
+	Priority     *TaskPriority `json:"priority" validate:"omitempty,oneof=low medium high"`
+	DueDate      *time.Time    `json:"dueDate"`
+	AutoComplete *bool         `json:"autoComplete"`
+	Recurrence   *string       `json:"recurrence" validate:"omitempty,max=255"`
+	Tags         []string      `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+	ProjectID    *uint         `json:"projectId"`
+	Position     *int          `json:"position" validate:"omitempty,min=0"`
 }
 
 // UpdateTaskRequest represents the request payload for updating a task
 type UpdateTaskRequest struct {
-	Title       *string       `json:"title" validate:"omitempty,min=1,max=200"`
-	Description *string       `json:"description"`
-	Status      *TaskStatus   `json:"status" validate:"omitempty,oneof=pending completed"`
-	Priority    *TaskPriority `json:"priority" validate:"omitempty,oneof=low medium high"`
-	DueDate     *time.Time    `json:"dueDate"`
+	Title        *string       `json:"title" validate:"omitempty,min=1,max=200"`
+	Description  *string       `json:"description"`
+	Status       *TaskStatus   `json:"status" validate:"omitempty,oneof=pending completed"`
+	Priority     *TaskPriority `json:"priority" validate:"omitempty,oneof=low medium high"`
+	DueDate      *time.Time    `json:"dueDate"`
+	AutoComplete *bool         `json:"autoComplete"`
+	Recurrence   *string       `json:"recurrence" validate:"omitempty,max=255"`
+	Position     *int          `json:"position" validate:"omitempty,min=0"`
+	// Tags replaces the task's tags when present; an empty list removes them all
+	Tags *[]string `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+	// ProjectID moves the task and its subtasks to another project; 0 makes it private
+	ProjectID *uint `json:"projectId"`
+	// ExpectedVersion comes from the If-Match header; the update fails with "version mismatch"
+	// unless the task is still at this version
+	ExpectedVersion *uint `json:"-"`
+}
+
+// TaskDocument is the patchable representation of a task that PATCH requests are applied
+// to. Its rules match CreateTaskRequest; null clears description, dueDate, recurrence and
+// projectId.
+type TaskDocument struct {
+	Title        string       `json:"title" validate:"required,min=1,max=200"`
+	Description  *string      `json:"description"`
+	Status       TaskStatus   `json:"status" validate:"required,oneof=pending completed"`
+	Priority     TaskPriority `json:"priority" validate:"required,oneof=low medium high"`
+	DueDate      *time.Time   `json:"dueDate"`
+	AutoComplete bool         `json:"autoComplete"`
+	Recurrence   *string      `json:"recurrence" validate:"omitempty,max=255"`
+	Tags         []string     `json:"tags" validate:"max=20,dive,min=1,max=50"`
+	ProjectID    *uint        `json:"projectId"`
+	Position     int          `json:"position" validate:"min=0"`
+}
+
+// NewTaskDocument returns the patchable fields of a task; its tags must be loaded
+func NewTaskDocument(task *Task) TaskDocument {
+	tags := make([]string, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return TaskDocument{
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       task.Status,
+		Priority:     task.Priority,
+		DueDate:      task.DueDate,
+		AutoComplete: task.AutoComplete,
+		Recurrence:   task.Recurrence,
+		Tags:         tags,
+		ProjectID:    task.ProjectID,
+		Position:     task.Position,
+	}
+}
+
+// AssignTaskRequest represents the request payload for assigning a task
+type AssignTaskRequest struct {
+	AssigneeID uint `json:"assigneeId" validate:"required"`
 }
 
+const (
+	AssigneeMe   = "me"
+	AssigneeNone = "none"
+)
+
+const (
+	TaskViewFlat   = "flat"
+	TaskViewNested = "nested"
+)
+
 // TaskFilter represents filter options for querying tasks
 type TaskFilter struct {
 	Status   *TaskStatus   `form:"status" validate:"omitempty,oneof=pending completed"`
 	Priority *TaskPriority `form:"priority" validate:"omitempty,oneof=low medium high"`
 	Overdue  *bool         `form:"overdue"`
-	Page     int           `form:"page" validate:"min=1"`
-	Limit    int           `form:"limit" validate:"min=1,max=100"`
+	// ProjectID limits results to one project; 0 selects private tasks
+	ProjectID *uint `form:"projectId"`
+	// Assignee is "me", "none" or a user ID
+	Assignee string `form:"assignee"`
+	// Q searches title and description; results are ordered by relevance
+	Q string `form:"q" validate:"omitempty,max=200"`
+	// Tags is a comma-separated list of tag names; TagMode "any" (default) matches tasks
+	// with at least one of them, "all" matches tasks with every one
+	Tags    string `form:"tags" validate:"omitempty,max=500"`
+	TagMode string `form:"tagMode" validate:"omitempty,oneof=any all"`
+	// View selects "flat" (every task, subtasks included) or "nested" (top-level tasks with their subtasks)
+	View  string `form:"view" validate:"omitempty,oneof=flat nested"`
+	Page  int    `form:"page" validate:"min=1"`
+	Limit int    `form:"limit" validate:"min=1,max=100"`
+	// Cursor continues from a nextCursor or prevCursor of an earlier page instead of Page
+	Cursor string `form:"cursor" validate:"omitempty,max=200"`
+	// Count includes the total in the response; it defaults to true for offset paging only
+	Count *bool `form:"count"`
+	// Sort is a comma-separated list of sort fields, each descending when prefixed with "-";
+	// see TaskSortFields
+	Sort string `form:"sort" validate:"omitempty,max=200"`
+	// Fields limits each task in the response to these comma-separated JSON fields
+	Fields string `form:"fields" validate:"omitempty,max=500"`
+	// Filter is a filter expression, e.g. "priority:high AND (due<2026-11-01 OR status:pending)";
+	// see package filter
+	Filter string `form:"filter" validate:"omitempty,max=1000"`
+}
+
+// CountTotal reports whether the total number of matching tasks should be counted
+func (f *TaskFilter) CountTotal() bool {
+	if f.Count != nil {
+		return *f.Count
+	}
+	return f.Cursor == ""
+}
+
+// TaskPage is one page of a task listing. Total is nil when it was not counted, and the
+// cursors are empty when there is no page in that direction.
+type TaskPage struct {
+	Tasks      []Task
+	Total      *int64
+	NextCursor string
+	PrevCursor string
 }
 
 // TaskStats represents task statistics
@@ -107,6 +260,139 @@ type TaskStats struct {
 	Pending   int64 `json:"pending"`
 	Completed int64 `json:"completed"`
 	Overdue   int64 `json:"overdue"`
+	// CreatedByMe and AssignedToMe split the counts by the user's relation to the task
+	CreatedByMe  TaskCounts `json:"createdByMe"`
+	AssignedToMe TaskCounts `json:"assignedToMe"`
+	// Subtasks and SubtasksCompleted count checklist steps across all parent tasks
+	Subtasks          int64 `json:"subtasks"`
+	SubtasksCompleted int64 `json:"subtasksCompleted"`
+	// Progress is the average completion percentage of top-level tasks, where a task
+	// with subtasks counts the share of its completed subtasks
+	Progress float64 `json:"progress"`
+	// ByTag breaks the counts down per tag
+	ByTag []TagStats `json:"byTag"`
+}
+
+// TaskCounts represents task counts by status
+type TaskCounts struct {
+	Total     int64 `json:"total"`
+	Pending   int64 `json:"pending"`
+	Completed int64 `json:"completed"`
+	Overdue   int64 `json:"overdue"`
+}
+
+// AssigneeUserID resolves the Assignee filter for the current user. It returns nil for
+// "none" and an error when the value is neither "me", "none" nor a user ID.
+func (f *TaskFilter) AssigneeUserID(currentUserID uint) (*uint, error) {
+	switch f.Assignee {
+	case AssigneeMe:
+		return &currentUserID, nil
+	case AssigneeNone:
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(f.Assignee, 10, 32)
+	if err != nil || id == 0 {
+		return nil, errors.New("assignee must be me, none or a user ID")
+	}
+	assigneeID := uint(id)
+	return &assigneeID, nil
+}
+
+// TagNames splits the comma-separated Tags filter into tag names
+func (f *TaskFilter) TagNames() []string {
+	var names []string
+	for _, name := range strings.Split(f.Tags, ",") {
+		if name = NormalizeTagName(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Expression parses Filter, returning nil when it is empty
+func (f *TaskFilter) Expression() (filter.Expr, error) {
+	if strings.TrimSpace(f.Filter) == "" {
+		return nil, nil
+	}
+	return filter.Parse(f.Filter)
+}
+
+// TaskSortFields are the fields tasks can be sorted by. Tasks without a due date sort last
+// in either direction.
+var TaskSortFields = []string{"dueDate", "priority", "createdAt", "updatedAt", "title", "position"}
+
+// DefaultTaskSort orders tasks by priority (high to low), then newest first
+const DefaultTaskSort = "-priority,-createdAt"
+
+// SortField is one key of a sort order
+type SortField struct {
+	Name       string
+	Descending bool
+}
+
+// SortFields parses Sort, falling back to DefaultTaskSort. Unknown and repeated fields are
+// rejected.
+func (f *TaskFilter) SortFields() ([]SortField, error) {
+	sort := f.Sort
+	if strings.TrimSpace(sort) == "" {
+		sort = DefaultTaskSort
+	}
+	var fields []SortField
+	seen := map[string]bool{}
+	for _, item := range strings.Split(sort, ",") {
+		item = strings.TrimSpace(item)
+		field := SortField{Name: strings.TrimPrefix(item, "-"), Descending: strings.HasPrefix(item, "-")}
+		if !containsString(TaskSortFields, field.Name) {
+			return nil, fmt.Errorf("unknown sort field %q; expected one of %s", field.Name, strings.Join(TaskSortFields, ", "))
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("sort field %q is repeated", field.Name)
+		}
+		seen[field.Name] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// FieldNames parses Fields, returning nil when every field is wanted. Names must be JSON
+// fields of Task.
+func (f *TaskFilter) FieldNames() ([]string, error) {
+	if strings.TrimSpace(f.Fields) == "" {
+		return nil, nil
+	}
+	var names []string
+	for _, name := range strings.Split(f.Fields, ",") {
+		name = strings.TrimSpace(name)
+		if !containsString(taskFieldNames, name) {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// taskFieldNames lists the JSON names of Task's fields
+var taskFieldNames = func() []string {
+	var names []string
+	taskType := reflect.TypeOf(Task{})
+	for i := 0; i < taskType.NumField(); i++ {
+		name := strings.Split(taskType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}()
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 // BeforeCreate sets default values before creating a task
@@ -117,5 +403,8 @@ func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	if t.Status == "" {
 		t.Status = StatusPending
 	}
+	if t.Version == 0 {
+		t.Version = 1
+	}
 	return nil
 }
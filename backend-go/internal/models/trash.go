@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TrashFilter represents pagination options for the trash
+type TrashFilter struct {
+	Page  int `form:"page" validate:"min=1"`
+	Limit int `form:"limit" validate:"min=1,max=100"`
+}
+
+// TrashedTask is a deleted task as listed in the trash
+type TrashedTask struct {
+	Task
+	DeletedAt time.Time `json:"deletedAt"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type User struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Email        string         `json:"email" gorm:"uniqueIndex;not null" validate:"required,email,max=255"`
+	Name         string         `json:"name" gorm:"not null" validate:"required,min=1,max=100"`
+	PasswordHash string         `json:"-" gorm:"not null"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName returns the table name for the User model
+func (User) TableName() string {
+	return "users"
+}
+
+// SetPassword hashes the plain text password with bcrypt and stores the hash
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// CheckPassword reports whether the plain text password matches the stored hash
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// NormalizeEmail lower-cases and trims an email address so lookups are case-insensitive
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RegisterRequest represents the request payload for creating an account
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Name     string `json:"name" validate:"required,min=1,max=100"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// LoginRequest represents the request payload for logging in
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// AuthResponse represents the response payload for a successful login, registration or refresh
+type AuthResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+	User             *User     `json:"user,omitempty"`
+}
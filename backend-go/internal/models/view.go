@@ -0,0 +1,119 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ViewFilter is the filtering part of a TaskFilter that a saved view stores. Its fields
+// have the same meaning as the matching GET /tasks query parameters.
+type ViewFilter struct {
+	Status    *TaskStatus   `json:"status,omitempty" validate:"omitempty,oneof=pending completed"`
+	Priority  *TaskPriority `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	Overdue   *bool         `json:"overdue,omitempty"`
+	ProjectID *uint         `json:"projectId,omitempty"`
+	Assignee  string        `json:"assignee,omitempty"`
+	Q         string        `json:"q,omitempty" validate:"omitempty,max=200"`
+	Tags      string        `json:"tags,omitempty" validate:"omitempty,max=500"`
+	TagMode   string        `json:"tagMode,omitempty" validate:"omitempty,oneof=any all"`
+	View      string        `json:"view,omitempty" validate:"omitempty,oneof=flat nested"`
+	Filter    string        `json:"filter,omitempty" validate:"omitempty,max=1000"`
+}
+
+// Value implements driver.Valuer
+func (f ViewFilter) Value() (driver.Value, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (f *ViewFilter) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = ViewFilter{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("cannot scan %T into ViewFilter", value)
+	}
+}
+
+// Apply copies the view's filters and sort onto a task filter, replacing any set there
+func (f ViewFilter) Apply(filter *TaskFilter, sort string) {
+	filter.Status = f.Status
+	filter.Priority = f.Priority
+	filter.Overdue = f.Overdue
+	filter.ProjectID = f.ProjectID
+	filter.Assignee = f.Assignee
+	filter.Q = f.Q
+	filter.Tags = f.Tags
+	filter.TagMode = f.TagMode
+	filter.View = f.View
+	filter.Filter = f.Filter
+	filter.Sort = sort
+}
+
+// SavedView is a named task query a user can run again
+type SavedView struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"userId" gorm:"not null;uniqueIndex:idx_saved_views_user_name"`
+	Name      string     `json:"name" gorm:"size:100;not null;uniqueIndex:idx_saved_views_user_name"`
+	Filter    ViewFilter `json:"filter" gorm:"type:text;not null"`
+	Sort      string     `json:"sort" gorm:"size:200;not null;default:''"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// TableName returns the table name for the SavedView model
+func (SavedView) TableName() string {
+	return "saved_views"
+}
+
+// SavedViewRequest represents the request payload for creating a saved view
+type SavedViewRequest struct {
+	Name   string     `json:"name" validate:"required,min=1,max=100"`
+	Filter ViewFilter `json:"filter"`
+	Sort   string     `json:"sort" validate:"omitempty,max=200"`
+}
+
+// UpdateSavedViewRequest represents the request payload for changing a saved view. A filter
+// replaces the stored one as a whole.
+type UpdateSavedViewRequest struct {
+	Name   *string     `json:"name" validate:"omitempty,min=1,max=100"`
+	Filter *ViewFilter `json:"filter"`
+	Sort   *string     `json:"sort" validate:"omitempty,max=200"`
+}
+
+// BuiltInView is a view every user has. Its filter is resolved whenever it runs, so relative
+// dates such as "today" always mean the current day.
+type BuiltInView struct {
+	ID     string     `json:"id"`
+	Name   string     `json:"name"`
+	Filter ViewFilter `json:"filter"`
+	Sort   string     `json:"sort"`
+}
+
+// BuiltInViews are the virtual views served alongside each user's saved views
+var BuiltInViews = []BuiltInView{
+	{ID: "today", Name: "Today", Filter: ViewFilter{Filter: "status:pending AND due:today"}, Sort: "dueDate,-priority"},
+	{ID: "overdue", Name: "Overdue", Filter: ViewFilter{Filter: "due:overdue"}, Sort: "dueDate,-priority"},
+	{ID: "high-priority-this-week", Name: "High priority this week", Filter: ViewFilter{Filter: "status:pending AND priority:high AND due:this-week"}, Sort: "dueDate"},
+}
+
+// FindBuiltInView returns the built-in view with the given ID
+func FindBuiltInView(id string) (*BuiltInView, bool) {
+	for i := range BuiltInViews {
+		if BuiltInViews[i].ID == id {
+			return &BuiltInViews[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StringList is a list of strings, such as event types, stored as a JSON array
+type StringList []string
+
+// Value implements driver.Valuer
+func (t StringList) Value() (driver.Value, error) {
+	if t == nil {
+		t = StringList{}
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (t *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = StringList{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+}
+
+// Includes reports whether the list contains a value
+func (t StringList) Includes(value string) bool {
+	for _, item := range t {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONDocument is a JSON document stored as text and written out as-is
+type JSONDocument string
+
+// MarshalJSON implements json.Marshaler
+func (d JSONDocument) MarshalJSON() ([]byte, error) {
+	if d == "" {
+		return []byte("null"), nil
+	}
+	return []byte(d), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *JSONDocument) UnmarshalJSON(data []byte) error {
+	*d = JSONDocument(data)
+	return nil
+}
+
+// Webhook is a URL a user registered to be called when tasks they can see change
+type Webhook struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"userId" gorm:"not null;index"`
+	URL        string     `json:"url" gorm:"size:2000;not null"`
+	Secret     string     `json:"-" gorm:"size:100;not null"`
+	EventTypes StringList `json:"events" gorm:"type:text;not null"`
+	Active     bool       `json:"active" gorm:"not null"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// TableName returns the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// CreatedWebhook is a newly registered webhook along with its signing secret, which is
+// only shown once
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookRequest represents the request payload for registering a webhook
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2000"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=task.created task.updated task.deleted task.completed task.reopened task.assigned task.unassigned task.restored task.reminder task.digest"`
+	Active *bool    `json:"active"`
+}
+
+// UpdateWebhookRequest represents the request payload for changing a webhook. Events, when
+// given, replace the selected event types.
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" validate:"omitempty,http_url,max=2000"`
+	Events []string `json:"events" validate:"omitempty,min=1,dive,oneof=task.created task.updated task.deleted task.completed task.reopened task.assigned task.unassigned task.restored task.reminder task.digest"`
+	Active *bool    `json:"active"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead marks a delivery that failed every attempt; it is only retried on request
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// WebhookDelivery is one event queued for, or sent to, a webhook
+type WebhookDelivery struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	WebhookID      uint           `json:"webhookId" gorm:"not null;index;uniqueIndex:idx_webhook_deliveries_key,priority:1"`
+	IdempotencyKey *string        `json:"idempotencyKey" gorm:"size:100;uniqueIndex:idx_webhook_deliveries_key,priority:2"`
+	EventType      string         `json:"event" gorm:"size:50;not null"`
+	Payload        JSONDocument   `json:"payload" gorm:"type:text;not null"`
+	Status         DeliveryStatus `json:"status" gorm:"size:20;not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int            `json:"attempts" gorm:"not null"`
+	NextAttemptAt  time.Time      `json:"nextAttemptAt" gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+	LastAttemptAt  *time.Time     `json:"lastAttemptAt"`
+	ResponseStatus *int           `json:"responseStatus"`
+	LastError      string         `json:"lastError" gorm:"size:1000;not null;default:''"`
+	DeliveredAt    *time.Time     `json:"deliveredAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// TableName returns the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// DeliveryFilter represents the status filter and pagination options for a delivery log
+type DeliveryFilter struct {
+	Status string `form:"status" validate:"omitempty,oneof=pending succeeded dead"`
+	Page   int    `form:"page" validate:"min=1"`
+	Limit  int    `form:"limit" validate:"min=1,max=100"`
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InAppNotifier stores messages as notifications shown inside the app
+type InAppNotifier struct {
+	db *gorm.DB
+}
+
+func NewInAppNotifier(db *gorm.DB) *InAppNotifier {
+	return &InAppNotifier{db: db}
+}
+
+// Notify stores a message unless one with the same key was stored for the user before
+func (n *InAppNotifier) Notify(ctx context.Context, msg *Message) error {
+	notification := models.Notification{
+		UserID: msg.UserID,
+		Key:    msg.Key,
+		Type:   msg.Type,
+		Title:  msg.Subject,
+		Body:   msg.Body,
+	}
+	if len(msg.TaskIDs) == 1 {
+		notification.TaskID = &msg.TaskIDs[0]
+	}
+	if err := n.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&notification).Error; err != nil {
+		return fmt.Errorf("failed to store notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Package notify delivers reminders and digests to users over the channels they chose
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// Message types, which webhooks select like task events
+const (
+	TypeReminder = "task.reminder"
+	TypeDigest   = "task.digest"
+)
+
+// Message is a notification for one user. Key identifies it across retries, so channels
+// that store messages keep each one once.
+type Message struct {
+	Key     string    `json:"key"`
+	Type    string    `json:"type"`
+	UserID  uint      `json:"userId"`
+	Email   string    `json:"-"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	TaskIDs []uint    `json:"taskIds"`
+	At      time.Time `json:"at"`
+}
+
+// Notifier delivers messages over one channel
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
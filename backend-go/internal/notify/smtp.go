@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds a whole conversation with the mail server
+const smtpTimeout = 30 * time.Second
+
+// SMTPNotifier sends messages as plain text email. It upgrades the connection with
+// STARTTLS when the server offers it and authenticates when credentials are set.
+type SMTPNotifier struct {
+	host   string
+	addr   string
+	from   string
+	sender string
+	auth   smtp.Auth
+}
+
+// NewSMTPNotifier creates a notifier that sends through host:port. From may include a
+// display name, as in "Task Manager <noreply@example.com>".
+func NewSMTPNotifier(host string, port int, username, password, from string) *SMTPNotifier {
+	notifier := &SMTPNotifier{host: host, addr: net.JoinHostPort(host, strconv.Itoa(port)), from: from, sender: from}
+	if address, err := mail.ParseAddress(from); err == nil {
+		notifier.sender = address.Address
+	}
+	if username != "" {
+		notifier.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return notifier
+}
+
+// Notify emails a message to the user's address
+func (n *SMTPNotifier) Notify(ctx context.Context, msg *Message) error {
+	if msg.Email == "" {
+		return errors.New("user has no email address")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail server: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet mail server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if n.auth != nil {
+		if err := client.Auth(n.auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(n.sender); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.Email); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.compose(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose formats a message as an RFC 5322 email with a UTF-8 text body
+func (n *SMTPNotifier) compose(msg *Message) []byte {
+	// Task titles end up in the subject, so line breaks must not start new headers
+	subject := strings.Join(strings.Fields(msg.Subject), " ")
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.Email)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", msg.At.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
@@ -0,0 +1,154 @@
+// Package realtime fans task changes out to connected clients
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event is a message delivered to the users it is addressed to. IDs increase by one per
+// published event and restart when the process does.
+type Event struct {
+	ID      uint64
+	Type    string
+	Data    json.RawMessage
+	UserIDs []uint
+	At      time.Time
+}
+
+func (e *Event) addressedTo(userID uint) bool {
+	for _, id := range e.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Bus is an in-process publish/subscribe hub. It keeps the most recent events so that a
+// client reconnecting with the last ID it saw can catch up on what it missed.
+type Bus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	replay      []Event
+	replaySize  int
+	queueSize   int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus creates a bus that keeps replaySize events for resuming and queues up to queueSize
+// undelivered events per subscriber
+func NewBus(replaySize, queueSize int) *Bus {
+	return &Bus{
+		replaySize:  replaySize,
+		queueSize:   queueSize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription is one client's stream of events
+type Subscription struct {
+	userID  uint
+	events  chan Event
+	dropped chan struct{}
+}
+
+// Events delivers the events addressed to the subscriber
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped is closed when the bus gave up on a subscriber that fell too far behind
+func (s *Subscription) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+// Publish delivers an event to the subscribed users it is addressed to. Subscribers whose
+// queue is full are dropped rather than allowed to hold up everyone else.
+func (b *Bus) Publish(userIDs []uint, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Data: payload, UserIDs: userIDs, At: time.Now()}
+	if b.replaySize > 0 {
+		if len(b.replay) == b.replaySize {
+			copy(b.replay, b.replay[1:])
+			b.replay = b.replay[:len(b.replay)-1]
+		}
+		b.replay = append(b.replay, event)
+	}
+
+	for sub := range b.subscribers {
+		if !event.addressedTo(sub.userID) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Replay holds the buffered events a resuming subscriber missed
+type Replay struct {
+	Events []Event
+	// Complete is false when some missed events are no longer buffered; the client then has
+	// to reload its state
+	Complete bool
+	// LastID is the ID of the latest event published before the subscription started
+	LastID uint64
+}
+
+// Subscribe registers a user's stream. With a lastEventID it also returns the events
+// addressed to the user that were published after that ID.
+func (b *Bus) Subscribe(userID uint, lastEventID *uint64) (*Subscription, Replay) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		userID:  userID,
+		events:  make(chan Event, b.queueSize),
+		dropped: make(chan struct{}),
+	}
+	b.subscribers[sub] = struct{}{}
+
+	replay := Replay{Complete: true, LastID: b.lastID}
+	if lastEventID == nil || *lastEventID == b.lastID {
+		return sub, replay
+	}
+	// An ID ahead of the bus was issued before a restart
+	if *lastEventID > b.lastID || len(b.replay) == 0 || b.replay[0].ID > *lastEventID+1 {
+		replay.Complete = false
+	}
+	if *lastEventID < b.lastID {
+		for _, event := range b.replay {
+			if event.ID > *lastEventID && event.addressedTo(userID) {
+				replay.Events = append(replay.Events, event)
+			}
+		}
+	}
+	return sub, replay
+}
+
+// Unsubscribe removes a subscription
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers, sub)
+}
+
+// drop removes a subscriber that stopped keeping up; the caller holds b.mu
+func (b *Bus) drop(sub *Subscription) {
+	delete(b.subscribers, sub)
+	close(sub.dropped)
+}
@@ -0,0 +1,333 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules used by
+// recurring tasks: FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, BYDAY, COUNT and UNTIL.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// maxPeriods bounds how many days, weeks or months are scanned when expanding a rule
+const maxPeriods = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ByDay is a BYDAY entry. Ordinal is only meaningful for MONTHLY rules, where 1 means
+// the first such weekday of the month and -1 the last; 0 means every such weekday.
+type ByDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []ByDay
+	Count    int
+	Until    *time.Time
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, errors.New("empty rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(val) {
+			case Daily, Weekly, Monthly:
+				rule.Freq = Frequency(val)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			days, err := parseByDay(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.ByDay = days
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("COUNT and UNTIL cannot both be set")
+	}
+	if rule.Freq != Monthly {
+		for _, d := range rule.ByDay {
+			if d.Ordinal != 0 {
+				return nil, fmt.Errorf("BYDAY ordinals are only supported with FREQ=MONTHLY")
+			}
+		}
+	}
+	return rule, nil
+}
+
+func parseUntil(val string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, val, time.UTC); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", val)
+}
+
+func parseByDay(val string) ([]ByDay, error) {
+	var days []ByDay
+	for _, item := range strings.Split(val, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+		weekday, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+		day := ByDay{Weekday: weekday}
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY %q", item)
+			}
+			day.Ordinal = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// String formats the rule in canonical RRULE form
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			code := strings.ToUpper(d.Weekday.String()[:2])
+			if d.Ordinal != 0 {
+				code = strconv.Itoa(d.Ordinal) + code
+			}
+			codes = append(codes, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrence is a single occurrence of a rule. Index is 1-based and counts from the
+// series start, which is what COUNT limits.
+type Occurrence struct {
+	Index int       `json:"index"`
+	Time  time.Time `json:"dueDate"`
+}
+
+// After returns up to n occurrences of the series starting at start that fall strictly
+// after the given time. The series start itself is always the first occurrence.
+func (r *Rule) After(start, after time.Time, n int) []Occurrence {
+	var result []Occurrence
+	r.each(start, func(o Occurrence) bool {
+		if o.Time.After(after) {
+			result = append(result, o)
+		}
+		return len(result) < n
+	})
+	return result
+}
+
+// Next returns the first occurrence strictly after the given time, or false when the
+// series has ended
+func (r *Rule) Next(start, after time.Time) (Occurrence, bool) {
+	occurrences := r.After(start, after, 1)
+	if len(occurrences) == 0 {
+		return Occurrence{}, false
+	}
+	return occurrences[0], true
+}
+
+// each calls fn for every occurrence in order until fn returns false or the series ends
+func (r *Rule) each(start time.Time, fn func(Occurrence) bool) {
+	index := 0
+	emit := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		index++
+		if !fn(Occurrence{Index: index, Time: t}) {
+			return false
+		}
+		return r.Count == 0 || index < r.Count
+	}
+
+	// The series start is always the first occurrence, as with DTSTART in RFC 5545
+	if !emit(start) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(start, period) {
+			if !t.After(start) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// candidates returns the sorted occurrence times in the given period after start
+func (r *Rule) candidates(start time.Time, period int) []time.Time {
+	switch r.Freq {
+	case Daily:
+		t := start.AddDate(0, 0, period*r.Interval)
+		if len(r.ByDay) > 0 && !r.matchesWeekday(t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+	case Weekly:
+		// Weeks start on Monday (WKST=MO)
+		offset := (int(start.Weekday()) + 6) % 7
+		weekStart := start.AddDate(0, 0, -offset+period*7*r.Interval)
+		if len(r.ByDay) == 0 {
+			return []time.Time{weekStart.AddDate(0, 0, offset)}
+		}
+		var times []time.Time
+		for i := 0; i < 7; i++ {
+			t := weekStart.AddDate(0, 0, i)
+			if r.matchesWeekday(t.Weekday()) {
+				times = append(times, t)
+			}
+		}
+		return times
+	case Monthly:
+		monthStart := time.Date(start.Year(), start.Month(), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location()).
+			AddDate(0, period*r.Interval, 0)
+		if len(r.ByDay) == 0 {
+			t := monthStart.AddDate(0, 0, start.Day()-1)
+			if t.Month() != monthStart.Month() {
+				// Months without this day are skipped, as RFC 5545 requires
+				return nil
+			}
+			return []time.Time{t}
+		}
+		return r.monthlyByDay(monthStart)
+	}
+	return nil
+}
+
+func (r *Rule) monthlyByDay(monthStart time.Time) []time.Time {
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	seen := make(map[int]bool)
+	var times []time.Time
+	for _, d := range r.ByDay {
+		var matches []int
+		for day := 1; day <= daysInMonth; day++ {
+			if monthStart.AddDate(0, 0, day-1).Weekday() == d.Weekday {
+				matches = append(matches, day)
+			}
+		}
+
+		var selected []int
+		switch {
+		case d.Ordinal > 0 && d.Ordinal <= len(matches):
+			selected = []int{matches[d.Ordinal-1]}
+		case d.Ordinal < 0 && -d.Ordinal <= len(matches):
+			selected = []int{matches[len(matches)+d.Ordinal]}
+		case d.Ordinal == 0:
+			selected = matches
+		}
+
+		for _, day := range selected {
+			if !seen[day] {
+				seen[day] = true
+				times = append(times, monthStart.AddDate(0, 0, day-1))
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+func (r *Rule) matchesWeekday(weekday time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+// Package search implements keyword search over task titles and descriptions.
+// PostgreSQL uses a GIN-indexed tsvector with ts_rank and ts_headline; other
+// dialects fall back to case-insensitive LIKE matching with a simple score.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// document is the text search vector over a task. The GIN index is built on this exact
+// expression so that PostgreSQL can use it for @@ matches.
+const document = "to_tsvector('english', coalesce(title, '') || ' ' || coalesce(description, ''))"
+
+const tsQuery = "websearch_to_tsquery('english', ?)"
+
+// ts_headline marks matches with private-use characters, which FormatHeadline swaps for <mark>
+// tags once the text around them is escaped
+const (
+	headlineStart   = "\ue000"
+	headlineStop    = "\ue001"
+	headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxWords=35, MinWords=15, MaxFragments=2"
+)
+
+// snippetRadius is the number of characters kept on each side of the first match
+// when snippets are built outside PostgreSQL
+const snippetRadius = 60
+
+// IsPostgres reports whether db talks to PostgreSQL
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// EnsureIndex creates the GIN index backing full-text search. It is a no-op on
+// dialects other than PostgreSQL.
+func EnsureIndex(db *gorm.DB) error {
+	if !IsPostgres(db) {
+		return nil
+	}
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks USING GIN (" + document + ")").Error
+}
+
+// Filter restricts query to tasks matching q
+func Filter(query *gorm.DB, q string) *gorm.DB {
+	if IsPostgres(query) {
+		return query.Where(document+" @@ "+tsQuery, q)
+	}
+
+	for _, term := range Terms(q) {
+		pattern := "%" + term + "%"
+		query = query.Where(
+			"(LOWER(title) LIKE ? OR LOWER(COALESCE(description, '')) LIKE ?)",
+			pattern, pattern,
+		)
+	}
+	return query
+}
+
+// Rank selects the search_rank and snippet columns for tasks matching q and orders
+// the most relevant tasks first. On PostgreSQL the snippet is produced by ts_headline and
+// has to go through FormatHeadline; elsewhere it is left empty for Highlight to fill in.
+func Rank(query *gorm.DB, q string) *gorm.DB {
+	if IsPostgres(query) {
+		return query.
+			Select(
+				"tasks.*, ts_rank("+document+", "+tsQuery+") AS search_rank, "+
+					"ts_headline('english', coalesce(title, '') || ' ' || coalesce(description, ''), "+tsQuery+", '"+headlineOptions+"') AS snippet",
+				q, q,
+			).
+			Order("search_rank DESC")
+	}
+
+	// A title match weighs twice as much as a description match
+	var parts []string
+	var args []interface{}
+	for _, term := range Terms(q) {
+		pattern := "%" + term + "%"
+		parts = append(parts,
+			"CASE WHEN LOWER(title) LIKE ? THEN 2 ELSE 0 END",
+			"CASE WHEN LOWER(COALESCE(description, '')) LIKE ? THEN 1 ELSE 0 END",
+		)
+		args = append(args, pattern, pattern)
+	}
+	if len(parts) == 0 {
+		return query
+	}
+	return query.
+		Select("tasks.*, ("+strings.Join(parts, " + ")+") AS search_rank", args...).
+		Order("search_rank DESC")
+}
+
+// Terms splits a query into lower-case words. Punctuation, quotes and the websearch
+// operators understood by PostgreSQL are ignored, so terms never contain LIKE wildcards.
+func Terms(q string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(fields))
+	terms := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field == "or" || seen[field] {
+			continue
+		}
+		seen[field] = true
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// FormatHeadline turns a ts_headline snippet into HTML: the task text is escaped and the
+// matches are wrapped in <mark> tags
+func FormatHeadline(headline string) string {
+	escaped := html.EscapeString(headline)
+	return strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>").Replace(escaped)
+}
+
+// Highlight returns an HTML excerpt of text around the first matching term with every
+// match wrapped in <mark> tags, mirroring FormatHeadline on PostgreSQL. The text itself
+// is escaped.
+func Highlight(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Case folding changed byte offsets; match case-sensitively instead
+		lower = text
+	}
+	first := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start, end := first-snippetRadius, first+snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	if end > len(text) {
+		end = len(text)
+	}
+	// Keep the excerpt on word boundaries
+	for start > 0 && text[start-1] != ' ' {
+		start--
+	}
+	for end < len(text) && text[end] != ' ' {
+		end++
+	}
+
+	excerpt := text[start:end]
+	lowerExcerpt := lower[start:end]
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("...")
+	}
+	plain := 0
+	for i := 0; i < len(excerpt); {
+		matched := ""
+		for _, term := range terms {
+			if strings.HasPrefix(lowerExcerpt[i:], term) && len(term) > len(matched) {
+				matched = term
+			}
+		}
+		if matched == "" {
+			i++
+			continue
+		}
+		b.WriteString(html.EscapeString(excerpt[plain:i]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(excerpt[i : i+len(matched)]))
+		b.WriteString("</mark>")
+		i += len(matched)
+		plain = i
+	}
+	b.WriteString(html.EscapeString(excerpt[plain:]))
+	if end < len(text) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
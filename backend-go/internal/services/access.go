@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// visibleTasksCondition matches the user's private tasks and every task in a project
+// the user is a member of
+const visibleTasksCondition = "((tasks.project_id IS NULL AND tasks.user_id = ?) OR " +
+	"tasks.project_id IN (SELECT project_id FROM project_members WHERE user_id = ?))"
+
+// visibleTo scopes a task query to the tasks the user may see
+func visibleTo(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(visibleTasksCondition, userID, userID)
+	}
+}
+
+// requireVisibleTask checks that the task exists and is visible to the user
+func requireVisibleTask(db *gorm.DB, userID, taskID uint) error {
+	var count int64
+	if err := db.Model(&models.Task{}).Scopes(visibleTo(userID)).Where("tasks.id = ?", taskID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+	if count == 0 {
+		return errors.New("task not found")
+	}
+	return nil
+}
+
+// projectRole returns the user's role in a project, or "project not found" when the
+// user is not a member
+func projectRole(db *gorm.DB, userID, projectID uint) (models.ProjectRole, error) {
+	var member models.ProjectMember
+	err := db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("project not found")
+		}
+		return "", fmt.Errorf("failed to get project membership: %w", err)
+	}
+	return member.Role, nil
+}
+
+// requireProjectEditor checks that the user may change tasks in the project
+func requireProjectEditor(db *gorm.DB, userID, projectID uint) error {
+	role, err := projectRole(db, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if !role.CanEdit() {
+		return errors.New("insufficient permissions")
+	}
+	return nil
+}
+
+// getEditableTask retrieves a task the user may change: a private task they own, or a
+// task in a project where they are an owner or editor. Viewers get "insufficient permissions".
+func (s *TaskService) getEditableTask(userID, taskID uint) (*models.Task, error) {
+	task, err := s.GetTaskByID(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireTaskEditor(userID, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// requireTaskEditor checks that the user may change a task they can see
+func (s *TaskService) requireTaskEditor(userID uint, task *models.Task) error {
+	if task.ProjectID == nil {
+		return nil
+	}
+	if err := requireProjectEditor(s.db, userID, *task.ProjectID); err != nil {
+		if err.Error() == "project not found" {
+			return errors.New("task not found")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AssignTask assigns a task to another user, recording who assigned it and when. The
+// assignee must be able to see the task: its creator for a private task, or a member
+// of the task's project.
+func (s *TaskService) AssignTask(userID, taskID, assigneeID uint) (*models.Task, error) {
+	task, err := s.getEditableTask(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetTaskByID(assigneeID, task.ID); err != nil {
+		if err.Error() == "task not found" {
+			return nil, errors.New("assignee cannot access task")
+		}
+		return nil, err
+	}
+
+	before := snapshotTask(task)
+	now := time.Now()
+	task.AssigneeID = &assigneeID
+	task.AssignedByID = &userID
+	task.AssignedAt = &now
+	if err := s.saveAssignment(userID, task, before, models.OperationAssign); err != nil {
+		if errors.Is(err, errVersionMismatch) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to assign task: %w", err)
+	}
+	return task, nil
+}
+
+// UnassignTask removes a task's assignee
+func (s *TaskService) UnassignTask(userID, taskID uint) (*models.Task, error) {
+	task, err := s.getEditableTask(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshotTask(task)
+	task.AssigneeID = nil
+	task.AssignedByID = nil
+	task.AssignedAt = nil
+	if err := s.saveAssignment(userID, task, before, models.OperationUnassign); err != nil {
+		if errors.Is(err, errVersionMismatch) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to unassign task: %w", err)
+	}
+	return task, nil
+}
+
+func (s *TaskService) saveAssignment(userID uint, task *models.Task, before taskSnapshot, operation models.TaskOperation) error {
+	return s.transaction(func(tx *gorm.DB) error {
+		err := updateTaskColumns(tx, task, map[string]interface{}{
+			"assignee_id":    task.AssigneeID,
+			"assigned_by_id": task.AssignedByID,
+			"assigned_at":    task.AssignedAt,
+		})
+		if err != nil {
+			return err
+		}
+		return recordTaskEvent(tx, userID, task.ID, operation, diffSnapshots(before, snapshotTask(task)))
+	})
+}
+
+// filterByAssignee restricts query to tasks assigned to assigneeID, or to unassigned
+// tasks when assigneeID is nil
+func filterByAssignee(query *gorm.DB, assigneeID *uint) *gorm.DB {
+	if assigneeID == nil {
+		return query.Where("tasks.assignee_id IS NULL")
+	}
+	return query.Where("tasks.assignee_id = ?", *assigneeID)
+}
+
+// countTasks returns status counts for the tasks matched by query
+func countTasks(query *gorm.DB) (models.TaskCounts, error) {
+	var counts models.TaskCounts
+	err := query.
+		Select(`COUNT(*) AS total,
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS pending,
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS completed,
+			COALESCE(SUM(CASE WHEN status <> ? AND due_date < ? THEN 1 ELSE 0 END), 0) AS overdue`,
+			models.StatusPending, models.StatusCompleted, models.StatusCompleted, time.Now()).
+		Scan(&counts).Error
+	return counts, err
+}
+
+// unassignInaccessible clears the assignee of a moved task and its subtasks where the
+// assignee can no longer see them
+func unassignInaccessible(tx *gorm.DB, task *models.Task) error {
+	query := tx.Model(&models.Task{}).
+		Where("(id = ? OR parent_id = ?) AND assignee_id IS NOT NULL", task.ID, task.ID)
+	if task.ProjectID == nil {
+		query = query.Where("assignee_id <> user_id")
+	} else {
+		query = query.Where("assignee_id NOT IN (SELECT user_id FROM project_members WHERE project_id = ?)", *task.ProjectID)
+	}
+	if err := query.Updates(map[string]interface{}{"assignee_id": nil, "assigned_by_id": nil, "assigned_at": nil, "version": bumpVersion}).Error; err != nil {
+		return err
+	}
+	return tx.First(task, task.ID).Error
+}
+
+// unassignMember clears the assignee of every task in a project assigned to a user who
+// is leaving it, since they can no longer see those tasks, and records it on each task
+func unassignMember(tx *gorm.DB, actorID, projectID, userID uint) error {
+	var tasks []models.Task
+	if err := tx.Where("project_id = ? AND assignee_id = ?", projectID, userID).Find(&tasks).Error; err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	err := tx.Model(&models.Task{}).
+		Where("project_id = ? AND assignee_id = ?", projectID, userID).
+		Updates(map[string]interface{}{"assignee_id": nil, "assigned_by_id": nil, "assigned_at": nil, "version": bumpVersion}).Error
+	if err != nil {
+		return err
+	}
+	return recordBulkUpdate(tx, actorID, tasks, models.OperationUnassign)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// errBatchAborted marks operations an atomic batch skipped after an earlier one failed
+var errBatchAborted = errors.New("batch aborted")
+
+// errBatchRolledBack makes the atomic batch transaction roll back
+var errBatchRolledBack = errors.New("batch rolled back")
+
+// RunBatch runs task operations in order through the regular TaskService methods, so each
+// one gets the same checks as its single-task endpoint. In atomic mode the operations share
+// one transaction: the first failure rolls back all of them and the rest are skipped with
+// "batch aborted". It returns each operation's task and error and whether the changes
+// were committed.
+func (s *TaskService) RunBatch(userID uint, operations []models.BatchOperation, atomic bool) ([]*models.Task, []error, bool) {
+	tasks := make([]*models.Task, len(operations))
+	errs := make([]error, len(operations))
+
+	if !atomic {
+		for i := range operations {
+			tasks[i], errs[i] = s.runBatchOperation(userID, &operations[i])
+		}
+		return tasks, errs, true
+	}
+
+	err := s.transaction(func(tx *gorm.DB) error {
+		// Nested service transactions become savepoints inside this one
+		txService := &TaskService{db: tx}
+		for i := range operations {
+			tasks[i], errs[i] = txService.runBatchOperation(userID, &operations[i])
+			if errs[i] != nil {
+				for j := i + 1; j < len(operations); j++ {
+					errs[j] = errBatchAborted
+				}
+				return errBatchRolledBack
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRolledBack) {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+	}
+	return tasks, errs, err == nil
+}
+
+func (s *TaskService) runBatchOperation(userID uint, operation *models.BatchOperation) (*models.Task, error) {
+	switch operation.Op {
+	case models.BatchCreate:
+		return s.CreateTask(userID, operation.Create)
+	case models.BatchUpdate:
+		operation.Update.ExpectedVersion = operation.Version
+		return s.UpdateTask(userID, operation.ID, operation.Update)
+	case models.BatchComplete:
+		return s.MarkTaskAsCompleted(userID, operation.ID)
+	case models.BatchDelete:
+		return nil, s.DeleteTask(userID, operation.ID)
+	default:
+		return nil, errors.New("unsupported batch operation")
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CommentService struct {
+	db    *gorm.DB
+	tasks *TaskService
+}
+
+func NewCommentService(db *gorm.DB, tasks *TaskService) *CommentService {
+	return &CommentService{db: db, tasks: tasks}
+}
+
+// GetComments returns a page of a task's comments, oldest first
+func (s *CommentService) GetComments(userID, taskID uint, filter *models.CommentFilter) (*models.CommentPage, error) {
+	if err := requireVisibleTask(s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.Where("task_id = ?", taskID)
+	if filter.Cursor != "" {
+		afterID, err := decodeCommentCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id > ?", afterID)
+	}
+
+	// Fetch one extra comment to learn whether another page follows
+	var comments []models.Comment
+	if err := query.Preload("Author").Order("id ASC").Limit(filter.Limit + 1).Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	page := &models.CommentPage{Comments: comments}
+	if len(comments) > filter.Limit {
+		page.Comments = comments[:filter.Limit]
+		page.NextCursor = encodeCommentCursor(page.Comments[filter.Limit-1].ID)
+	}
+	return page, nil
+}
+
+// CreateComment posts a comment on a task. Anyone who can see the task may comment.
+func (s *CommentService) CreateComment(userID, taskID uint, req *models.CommentRequest) (*models.Comment, error) {
+	if err := requireVisibleTask(s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{TaskID: taskID, UserID: userID, Body: req.Body}
+	err := s.tasks.transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return adjustCommentCount(tx, userID, comment, 1)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	if err := s.db.Preload("Author").First(comment, comment.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// UpdateComment edits the body of the user's own comment
+func (s *CommentService) UpdateComment(userID, taskID, commentID uint, req *models.CommentRequest) (*models.Comment, error) {
+	comment, err := s.getOwnComment(userID, taskID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Body = req.Body
+	if err := s.db.Omit("Author").Save(comment).Error; err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+	return comment, nil
+}
+
+// DeleteComment deletes the user's own comment (soft delete)
+func (s *CommentService) DeleteComment(userID, taskID, commentID uint) error {
+	comment, err := s.getOwnComment(userID, taskID, commentID)
+	if err != nil {
+		return err
+	}
+
+	err = s.tasks.transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(comment).Error; err != nil {
+			return err
+		}
+		return adjustCommentCount(tx, userID, comment, -1)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	return nil
+}
+
+func (s *CommentService) getOwnComment(userID, taskID, commentID uint) (*models.Comment, error) {
+	if err := requireVisibleTask(s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	var comment models.Comment
+	err := s.db.Preload("Author").Where("id = ? AND task_id = ?", commentID, taskID).First(&comment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	if comment.UserID != userID {
+		return nil, errors.New("insufficient permissions")
+	}
+	return &comment, nil
+}
+
+// adjustCommentCount changes a task's denormalized comment count when a comment is posted
+// or deleted, advances its version and records the comment on the task's history.
+// The column is read-only on the model so that saving a task never overwrites a concurrent change.
+func adjustCommentCount(tx *gorm.DB, userID uint, comment *models.Comment, delta int) error {
+	err := tx.Exec("UPDATE tasks SET comment_count = comment_count + ?, version = version + 1 WHERE id = ?", delta, comment.TaskID).Error
+	if err != nil {
+		return err
+	}
+	change := models.FieldChange{After: comment.ID}
+	if delta < 0 {
+		change = models.FieldChange{Before: comment.ID}
+	}
+	return recordTaskEvent(tx, userID, comment.TaskID, models.OperationUpdate, models.FieldChanges{"comments": change})
+}
+
+// deleteTaskComments soft-deletes the comments of the tasks matched by where
+func deleteTaskComments(tx *gorm.DB, where string, args ...interface{}) error {
+	taskIDs := tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&models.Task{}).Select("id").Where(where, args...)
+	return tx.Where("task_id IN (?)", taskIDs).Delete(&models.Comment{}).Error
+}
+
+func encodeCommentCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCommentCursor(cursor string) (uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	id, err := strconv.ParseUint(string(raw), 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	return uint(id), nil
+}
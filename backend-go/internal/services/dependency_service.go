@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// blockersReachSQL counts how often a task appears among the transitive blockers of a
+// starting task. UNION drops repeated rows, so the recursion ends even on cyclic data.
+// Postgres needs the seed cast to the column type of the recursive term.
+const blockersReachSQL = `WITH RECURSIVE chain(id) AS (
+	SELECT CAST(? AS BIGINT)
+	UNION
+	SELECT d.blocked_by_id FROM task_dependencies d JOIN chain c ON d.task_id = c.id
+) SELECT COUNT(*) FROM chain WHERE id = ?`
+
+// planEdgesSQL selects every dependency reachable from a set of tasks through blockers
+// that are not deleted
+const planEdgesSQL = `WITH RECURSIVE reach(id) AS (
+	SELECT id FROM tasks WHERE id IN ?
+	UNION
+	SELECT d.blocked_by_id FROM task_dependencies d
+	JOIN reach r ON d.task_id = r.id
+	JOIN tasks b ON b.id = d.blocked_by_id AND b.deleted_at IS NULL
+) SELECT d.task_id, d.blocked_by_id FROM task_dependencies d
+JOIN reach r ON d.task_id = r.id
+JOIN tasks b ON b.id = d.blocked_by_id AND b.deleted_at IS NULL`
+
+// GetDependencies lists the tasks a task is blocked by and the tasks it blocks. Tasks the
+// user cannot see are left out.
+func (s *TaskService) GetDependencies(userID, taskID uint) (*models.TaskDependencies, error) {
+	if err := requireVisibleTask(s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	deps := &models.TaskDependencies{BlockedBy: []models.Task{}, Blocking: []models.Task{}}
+	err := s.db.Scopes(visibleTo(userID)).
+		Where("tasks.id IN (SELECT blocked_by_id FROM task_dependencies WHERE task_id = ?)", taskID).
+		Order("tasks.id ASC").Find(&deps.BlockedBy).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockers: %w", err)
+	}
+	err = s.db.Scopes(visibleTo(userID)).
+		Where("tasks.id IN (SELECT task_id FROM task_dependencies WHERE blocked_by_id = ?)", taskID).
+		Order("tasks.id ASC").Find(&deps.Blocking).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked tasks: %w", err)
+	}
+	if err := markBlocked(s.db, deps.BlockedBy, deps.Blocking); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// AddDependency records that a task is blocked by another one. The user must be able to
+// change the task and see the blocker; a dependency that closes a cycle is rejected.
+func (s *TaskService) AddDependency(userID, taskID, blockedByID uint) (*models.TaskDependency, error) {
+	if taskID == blockedByID {
+		return nil, errors.New("task cannot block itself")
+	}
+	task, err := s.getEditableTask(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireVisibleTask(s.db, userID, blockedByID); err != nil {
+		return nil, errors.New("blocker not found")
+	}
+
+	dependency := &models.TaskDependency{TaskID: task.ID, BlockedByID: blockedByID, CreatedByID: userID}
+	err = s.transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.TaskDependency{}).
+			Where("task_id = ? AND blocked_by_id = ?", task.ID, blockedByID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errors.New("dependency already exists")
+		}
+		// The new edge closes a cycle when the task already blocks its blocker
+		var cycle int64
+		if err := tx.Raw(blockersReachSQL, blockedByID, task.ID).Scan(&cycle).Error; err != nil {
+			return err
+		}
+		if cycle > 0 {
+			return errors.New("dependency would create a cycle")
+		}
+		if err := tx.Create(dependency).Error; err != nil {
+			return err
+		}
+		if err := touchTasks(tx, []uint{task.ID}); err != nil {
+			return err
+		}
+		changes := models.FieldChanges{"blockedBy": {After: blockedByID}}
+		return recordTaskEvent(tx, userID, task.ID, models.OperationUpdate, changes)
+	})
+	if err != nil {
+		switch err.Error() {
+		case "dependency already exists", "dependency would create a cycle":
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to add dependency: %w", err)
+	}
+	return dependency, nil
+}
+
+// RemoveDependency removes a blocker from a task
+func (s *TaskService) RemoveDependency(userID, taskID, blockedByID uint) error {
+	task, err := s.getEditableTask(userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	err = s.transaction(func(tx *gorm.DB) error {
+		result := tx.Where("task_id = ? AND blocked_by_id = ?", task.ID, blockedByID).Delete(&models.TaskDependency{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("dependency not found")
+		}
+		if err := touchTasks(tx, []uint{task.ID}); err != nil {
+			return err
+		}
+		changes := models.FieldChanges{"blockedBy": {Before: blockedByID}}
+		return recordTaskEvent(tx, userID, task.ID, models.OperationUpdate, changes)
+	})
+	if err != nil {
+		if err.Error() == "dependency not found" {
+			return err
+		}
+		return fmt.Errorf("failed to remove dependency: %w", err)
+	}
+	return nil
+}
+
+// GetPlan orders a set of tasks into stages that respect their dependencies, including
+// ones that run through tasks outside the set. Each stage is sorted by priority, highest first.
+func (s *TaskService) GetPlan(userID uint, ids []uint) (*models.Plan, error) {
+	var tasks []models.Task
+	if err := s.db.Scopes(visibleTo(userID)).Where("tasks.id IN ?", ids).
+		Preload("Tags", orderTagsByName).Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	if len(tasks) != len(ids) {
+		return nil, errors.New("task not found")
+	}
+	if err := markBlocked(s.db, tasks); err != nil {
+		return nil, err
+	}
+
+	var edges []models.TaskDependency
+	if err := s.db.Raw(planEdgesSQL, ids).Scan(&edges).Error; err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+	blockers := map[uint][]uint{}
+	for _, edge := range edges {
+		blockers[edge.TaskID] = append(blockers[edge.TaskID], edge.BlockedByID)
+	}
+	inSet := map[uint]bool{}
+	for _, id := range ids {
+		inSet[id] = true
+	}
+
+	// depth counts the planned tasks on the longest blocker chain below a task
+	depths := map[uint]int{}
+	visiting := map[uint]bool{}
+	var depth func(id uint) int
+	depth = func(id uint) int {
+		if d, ok := depths[id]; ok {
+			return d
+		}
+		if visiting[id] {
+			return 0
+		}
+		visiting[id] = true
+		d := 0
+		for _, blocker := range blockers[id] {
+			below := depth(blocker)
+			if inSet[blocker] {
+				below++
+			}
+			if below > d {
+				d = below
+			}
+		}
+		visiting[id] = false
+		depths[id] = d
+		return d
+	}
+
+	plan := &models.Plan{Stages: [][]models.Task{}}
+	for _, task := range tasks {
+		d := depth(task.ID)
+		for len(plan.Stages) <= d {
+			plan.Stages = append(plan.Stages, []models.Task{})
+		}
+		plan.Stages[d] = append(plan.Stages[d], task)
+	}
+	for _, stage := range plan.Stages {
+		sort.Slice(stage, func(i, j int) bool {
+			if wi, wj := stage[i].GetPriorityWeight(), stage[j].GetPriorityWeight(); wi != wj {
+				return wi > wj
+			}
+			return stage[i].ID < stage[j].ID
+		})
+	}
+	return plan, nil
+}
+
+// markBlocked sets IsBlocked on tasks that have a pending blocker
+func markBlocked(db *gorm.DB, lists ...[]models.Task) error {
+	var ids []uint
+	for _, tasks := range lists {
+		for i := range tasks {
+			ids = append(ids, tasks[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var blocked []uint
+	err := db.Raw(`SELECT DISTINCT d.task_id FROM task_dependencies d
+		JOIN tasks b ON b.id = d.blocked_by_id
+		WHERE d.task_id IN ? AND b.status <> ? AND b.deleted_at IS NULL`, ids, models.StatusCompleted).
+		Scan(&blocked).Error
+	if err != nil {
+		return fmt.Errorf("failed to get blocked tasks: %w", err)
+	}
+	isBlocked := map[uint]bool{}
+	for _, id := range blocked {
+		isBlocked[id] = true
+	}
+	for _, tasks := range lists {
+		for i := range tasks {
+			tasks[i].IsBlocked = isBlocked[tasks[i].ID]
+		}
+	}
+	return nil
+}
+
+// requireUnblocked refuses to complete a task while one of its blockers is pending
+func requireUnblocked(db *gorm.DB, task *models.Task) error {
+	tasks := []models.Task{{ID: task.ID}}
+	if err := markBlocked(db, tasks); err != nil {
+		return err
+	}
+	task.IsBlocked = tasks[0].IsBlocked
+	if task.IsBlocked {
+		return errors.New("task is blocked")
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type EventService struct {
+	db *gorm.DB
+}
+
+func NewEventService(db *gorm.DB) *EventService {
+	return &EventService{db: db}
+}
+
+// GetTaskHistory returns a task's events, oldest first
+func (s *EventService) GetTaskHistory(userID, taskID uint) ([]models.TaskEvent, error) {
+	if err := requireVisibleTask(s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	events := []models.TaskEvent{}
+	err := s.db.Preload("Actor").Where("task_id = ?", taskID).Order("created_at ASC, id ASC").Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task history: %w", err)
+	}
+	return events, nil
+}
+
+// GetActivity returns the events on every task visible to the user, newest first. Events
+// on deleted tasks are included.
+func (s *EventService) GetActivity(userID uint, filter *models.ActivityFilter) ([]models.TaskEvent, int64, error) {
+	query := s.db.Model(&models.TaskEvent{}).
+		Joins("JOIN tasks ON tasks.id = task_events.task_id").
+		Where(visibleTasksCondition, userID, userID)
+	if filter.From != nil {
+		query = query.Where("task_events.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("task_events.created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count activity: %w", err)
+	}
+
+	events := []models.TaskEvent{}
+	err := query.Select("task_events.*").
+		Preload("Actor").
+		Order("task_events.created_at DESC, task_events.id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get activity: %w", err)
+	}
+	return events, total, nil
+}
+
+// taskSnapshot holds the audited fields of a task, keyed by their JSON names
+type taskSnapshot map[string]interface{}
+
+// snapshotTask captures the audited fields of a task. Pointers are dereferenced and times
+// formatted in UTC so that snapshots compare by value.
+func snapshotTask(task *models.Task) taskSnapshot {
+	return taskSnapshot{
+		"title":        task.Title,
+		"description":  derefString(task.Description),
+		"status":       task.Status,
+		"priority":     task.Priority,
+		"dueDate":      formatTime(task.DueDate),
+		"projectId":    derefUint(task.ProjectID),
+		"assigneeId":   derefUint(task.AssigneeID),
+		"parentId":     derefUint(task.ParentID),
+		"autoComplete": task.AutoComplete,
+		"position":     task.Position,
+		"recurrence":   derefString(task.Recurrence),
+	}
+}
+
+// withTags adds the task's tag names to a snapshot
+func (s taskSnapshot) withTags(tags []models.Tag) taskSnapshot {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	sort.Strings(names)
+	s["tags"] = names
+	return s
+}
+
+// diffSnapshots returns the fields whose values differ. With a nil before or after
+// snapshot it records every non-null field of the other as created or deleted.
+func diffSnapshots(before, after taskSnapshot) models.FieldChanges {
+	changes := models.FieldChanges{}
+	for field, value := range after {
+		old, ok := before[field]
+		if (!ok && value != nil) || (ok && !reflect.DeepEqual(old, value)) {
+			changes[field] = models.FieldChange{Before: old, After: value}
+		}
+	}
+	for field, value := range before {
+		if _, ok := after[field]; !ok && value != nil {
+			changes[field] = models.FieldChange{Before: value}
+		}
+	}
+	return changes
+}
+
+// recordTaskEvent writes an audit event for a task mutation. It must run in the same
+// transaction as the mutation so that the log never disagrees with the data.
+func recordTaskEvent(tx *gorm.DB, actorID, taskID uint, operation models.TaskOperation, changes models.FieldChanges) error {
+	event := &models.TaskEvent{
+		TaskID:    taskID,
+		ActorID:   actorID,
+		Operation: operation,
+		Changes:   changes,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return err
+	}
+	noteRecordedEvent(tx, event)
+	return nil
+}
+
+// recordBulkUpdate records an event for each task that a bulk update changed. before holds
+// the tasks as loaded ahead of the update; each is reloaded and diffed against it.
+func recordBulkUpdate(tx *gorm.DB, actorID uint, before []models.Task, operation models.TaskOperation) error {
+	for i := range before {
+		var after models.Task
+		if err := tx.First(&after, before[i].ID).Error; err != nil {
+			return err
+		}
+		if changes := diffSnapshots(snapshotTask(&before[i]), snapshotTask(&after)); len(changes) > 0 {
+			if err := recordTaskEvent(tx, actorID, after.ID, operation, changes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func derefString(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func derefUint(value *uint) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func formatTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// notificationOverdue is the type of the notice sent when a task becomes overdue
+	notificationOverdue = "task.overdue"
+	// titleLength bounds the title of a notification
+	titleLength = 300
+)
+
+// notificationVerbs describes each task event in a notification title
+var notificationVerbs = map[string]string{
+	"task.created":    "created",
+	"task.updated":    "updated",
+	"task.deleted":    "deleted",
+	"task.completed":  "completed",
+	"task.reopened":   "reopened",
+	"task.assigned":   "assigned",
+	"task.unassigned": "unassigned",
+	"task.restored":   "restored",
+}
+
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// GetNotifications returns a user's notifications, newest first, along with the total that
+// match the filter and the number of unread ones
+func (s *NotificationService) GetNotifications(userID uint, filter *models.NotificationFilter) ([]models.Notification, int64, int64, error) {
+	var unread int64
+	err := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread).Error
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if filter.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	notifications := []models.Notification{}
+	err = query.Order("id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	return notifications, total, unread, nil
+}
+
+// MarkRead marks one of a user's notifications as read. A notification that was already
+// read keeps the time it was first read.
+func (s *NotificationService) MarkRead(userID, notificationID uint) (*models.Notification, error) {
+	var notification models.Notification
+	err := s.db.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("notification not found")
+		}
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to mark notification read: %w", err)
+		}
+		notification.ReadAt = &now
+	}
+	return &notification, nil
+}
+
+// MarkAllRead marks every unread notification of a user as read and returns how many
+// it marked
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetSettings returns how a user wants to be notified
+func (s *NotificationService) GetSettings(userID uint) (*models.NotificationSettings, error) {
+	return loadNotificationSettings(s.db, userID)
+}
+
+// UpdateSettings changes the user's notification channels and daily digest
+func (s *NotificationService) UpdateSettings(userID uint, req *models.NotificationSettingsRequest) (*models.NotificationSettings, error) {
+	settings, err := loadNotificationSettings(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Channels != nil {
+		settings.Channels = req.Channels
+	}
+	if req.MutedTypes != nil {
+		settings.MutedTypes = req.MutedTypes
+	}
+	if req.DigestEnabled != nil {
+		settings.DigestEnabled = *req.DigestEnabled
+	}
+	if req.DigestHour != nil {
+		settings.DigestHour = *req.DigestHour
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return nil, errors.New("invalid timezone")
+		}
+		settings.Timezone = *req.Timezone
+	}
+
+	if err := s.db.Save(settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to update notification settings: %w", err)
+	}
+	return settings, nil
+}
+
+// loadNotificationSettings returns a user's settings, or the defaults when the user has
+// not changed them
+func loadNotificationSettings(db *gorm.DB, userID uint) (*models.NotificationSettings, error) {
+	var settings models.NotificationSettings
+	err := db.Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		settings = models.DefaultNotificationSettings(userID)
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// HandleOutboxEvent adds a notification about a task event to the inbox of the task's creator
+// and assignees, leaving out the user who made the change. A user who was unassigned is told
+// too. An event handled again is not stored twice.
+func (s *NotificationService) HandleOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	verb, ok := notificationVerbs[event.Type]
+	if !ok {
+		return nil
+	}
+
+	var payload models.EventPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return fmt.Errorf("failed to decode outbox event: %w", err)
+	}
+	task := payload.Data.Task
+	if task == nil {
+		// Deleted tasks are not in the payload
+		task = &models.Task{}
+		err := s.db.WithContext(ctx).Unscoped().First(task, payload.Data.TaskID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Purged tasks are gone, but their delete event still holds what the inbox needs
+			if task = purgedTask(&payload.Data, event.Audience); task == nil {
+				return nil
+			}
+		} else if err != nil {
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+	}
+
+	actorID := payload.Data.ActorID
+	candidates := []uint{task.UserID}
+	if task.AssigneeID != nil {
+		candidates = append(candidates, *task.AssigneeID)
+	}
+	if change, ok := payload.Data.Changes["assigneeId"]; ok {
+		if before, ok := change.Before.(float64); ok {
+			candidates = append(candidates, uint(before))
+		}
+	}
+	var recipients []uint
+	for _, userID := range candidates {
+		if userID != actorID && event.Audience.Includes(userID) && !models.UserIDs(recipients).Includes(userID) {
+			recipients = append(recipients, userID)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	var actor models.User
+	if err := s.db.WithContext(ctx).First(&actor, actorID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get actor: %w", err)
+	}
+	name := actor.Name
+	if name == "" {
+		name = "Someone"
+	}
+
+	body := ""
+	if event.Type == "task.updated" {
+		fields := make([]string, 0, len(payload.Data.Changes))
+		for field := range payload.Data.Changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		body = "Changed " + strings.Join(fields, ", ")
+	}
+
+	notifications := make([]models.Notification, 0, len(recipients))
+	for _, userID := range recipients {
+		settings, err := loadNotificationSettings(s.db.WithContext(ctx), userID)
+		if err != nil {
+			return err
+		}
+		if !settings.Wants(event.Type) {
+			continue
+		}
+		title := fmt.Sprintf("%s %s %q", name, verb, task.Title)
+		if event.Type == "task.assigned" && task.AssigneeID != nil && *task.AssigneeID == userID {
+			title += " to you"
+		}
+		notification := models.Notification{
+			UserID:  userID,
+			Key:     event.IdempotencyKey,
+			Type:    event.Type,
+			Title:   truncate(title, titleLength),
+			Body:    body,
+			ActorID: &actorID,
+		}
+		if task.ID != 0 {
+			notification.TaskID = &task.ID
+		}
+		notifications = append(notifications, notification)
+	}
+	_, err := s.store(ctx, notifications)
+	return err
+}
+
+// purgedTask rebuilds the fields of a permanently deleted task that a notification needs
+// from its delete event, leaving the ID unset. A private task is only visible to its
+// creator, so its audience names them.
+func purgedTask(change *models.TaskChange, audience models.UserIDs) *models.Task {
+	if change.Operation != models.OperationDelete {
+		return nil
+	}
+	task := &models.Task{}
+	task.Title, _ = change.Changes["title"].Before.(string)
+	if before, ok := change.Changes["assigneeId"].Before.(float64); ok {
+		assigneeID := uint(before)
+		task.AssigneeID = &assigneeID
+	}
+	if _, inProject := change.Changes["projectId"]; !inProject && len(audience) == 1 {
+		task.UserID = audience[0]
+	}
+	return task
+}
+
+// NotifyOverdue tells the users whose pending tasks are overdue and have not raised a notice
+// for their current due date yet, and returns how many notices it added. A task is the
+// user's when it is assigned to them, or when they created it and left it unassigned. Each
+// due date raises one notice, so moving it past a new due date raises another. Tasks are
+// marked once handled, so a notice is not lost when the job runs late or was down.
+func (s *NotificationService) NotifyOverdue(ctx context.Context) (int64, error) {
+	var tasks []models.Task
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND due_date IS NOT NULL AND due_date <= ?", models.StatusPending, time.Now()).
+		Where("overdue_notice_for IS NULL OR overdue_notice_for <> due_date").
+		Order("due_date ASC, id ASC").
+		Find(&tasks).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get overdue tasks: %w", err)
+	}
+
+	var notifications []models.Notification
+	for i := range tasks {
+		task := &tasks[i]
+		if !task.IsOverdue() {
+			continue
+		}
+		userID := task.UserID
+		if task.AssigneeID != nil {
+			userID = *task.AssigneeID
+		}
+		audience, err := taskAudience(s.db.WithContext(ctx), task)
+		if err != nil {
+			return 0, fmt.Errorf("failed to resolve the audience of task %d: %w", task.ID, err)
+		}
+		if !models.UserIDs(audience).Includes(userID) {
+			continue
+		}
+		settings, err := loadNotificationSettings(s.db.WithContext(ctx), userID)
+		if err != nil {
+			return 0, err
+		}
+		if !settings.Wants(notificationOverdue) {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID: userID,
+			Key:    "overdue-" + strconv.FormatUint(uint64(task.ID), 10) + "-" + strconv.FormatInt(task.DueDate.Unix(), 10),
+			Type:   notificationOverdue,
+			Title:  truncate(fmt.Sprintf("%q is overdue", task.Title), titleLength),
+			Body:   "Due " + task.DueDate.UTC().Format(time.RFC3339),
+			TaskID: &task.ID,
+		})
+	}
+	added, err := s.store(ctx, notifications)
+	if err != nil {
+		return 0, err
+	}
+
+	// Muted and unseen tasks are marked too, so that they are not looked at again
+	for i := range tasks {
+		err := s.db.WithContext(ctx).Model(&models.Task{}).
+			Where("id = ? AND due_date = ?", tasks[i].ID, tasks[i].DueDate).
+			UpdateColumn("overdue_notice_for", tasks[i].DueDate).Error
+		if err != nil {
+			return added, fmt.Errorf("failed to mark overdue task: %w", err)
+		}
+	}
+	return added, nil
+}
+
+// store adds notifications, skipping those a user already has under the same key, and
+// returns how many it added
+func (s *NotificationService) store(ctx context.Context, notifications []models.Notification) (int64, error) {
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&notifications)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to store notifications: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
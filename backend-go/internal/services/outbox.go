@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// firstRetryDelay is the delay after a first failed attempt; it doubles with every
+	// further attempt up to maxRetryDelay
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = time.Hour
+)
+
+// OutboxSink receives the task events relayed from the outbox. An event is handed over
+// at least once, so sinks use its idempotency key to ignore repeats.
+type OutboxSink interface {
+	HandleOutboxEvent(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// OutboxRelay hands committed outbox events to the sinks registered with it
+type OutboxRelay struct {
+	db          *gorm.DB
+	sinks       []namedSink
+	wake        chan struct{}
+	maxAttempts int
+}
+
+type namedSink struct {
+	name string
+	sink OutboxSink
+}
+
+// NewOutboxRelay creates a relay that hands each event to its sinks at most maxAttempts times
+func NewOutboxRelay(db *gorm.DB, maxAttempts int) *OutboxRelay {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &OutboxRelay{db: db, wake: make(chan struct{}, 1), maxAttempts: maxAttempts}
+}
+
+// AddSink registers a sink under a name used in errors and logs
+func (r *OutboxRelay) AddSink(name string, sink OutboxSink) {
+	r.sinks = append(r.sinks, namedSink{name: name, sink: sink})
+}
+
+// Notify tells the relay that new events were committed
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Wake receives a value after Notify was called
+func (r *OutboxRelay) Wake() <-chan struct{} {
+	return r.wake
+}
+
+// RelayDue hands up to limit due events to every sink, oldest first, and returns how many
+// it processed. An event that a sink fails is retried later with every sink, until it runs
+// out of attempts and is dead.
+func (r *OutboxRelay) RelayDue(ctx context.Context, limit int) (int, error) {
+	var due []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("processed_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", time.Now()).
+		Order("id ASC").
+		Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get outbox events: %w", err)
+	}
+
+	processed := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		claimed, err := r.claim(&due[i])
+		if err != nil {
+			return processed, err
+		}
+		if !claimed {
+			continue
+		}
+		if err := r.relay(ctx, &due[i]); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// PurgeProcessed deletes the events that were processed before the cutoff and returns how
+// many it deleted. Dead events are kept for inspection.
+func (r *OutboxRelay) PurgeProcessed(before time.Time) (int64, error) {
+	result := r.db.Where("processed_at IS NOT NULL AND processed_at < ?", before).Delete(&models.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// claim takes an event for one attempt, leaving it to another relay if that one claimed it
+// first. A relay that dies mid-attempt leaves the event to be retried after firstRetryDelay.
+func (r *OutboxRelay) claim(event *models.OutboxEvent) (bool, error) {
+	result := r.db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND processed_at IS NULL AND dead_at IS NULL AND attempts = ?", event.ID, event.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        event.Attempts + 1,
+			"next_attempt_at": time.Now().Add(firstRetryDelay),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim outbox event: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	event.Attempts++
+	return true, nil
+}
+
+// relay hands a claimed event to every sink and records the outcome
+func (r *OutboxRelay) relay(ctx context.Context, event *models.OutboxEvent) error {
+	var sinkErr error
+	for _, named := range r.sinks {
+		if err := named.sink.HandleOutboxEvent(ctx, event); err != nil {
+			sinkErr = fmt.Errorf("%s: %w", named.name, err)
+			break
+		}
+	}
+
+	updates := map[string]interface{}{"last_error": ""}
+	switch {
+	case sinkErr == nil:
+		updates["processed_at"] = time.Now()
+	case event.Attempts >= r.maxAttempts:
+		log.Printf("Giving up on outbox event %s after %d attempts: %v", event.IdempotencyKey, event.Attempts, sinkErr)
+		updates["dead_at"] = time.Now()
+		updates["last_error"] = truncate(sinkErr.Error(), lastErrorLength)
+	default:
+		log.Printf("Failed to relay outbox event %s: %v", event.IdempotencyKey, sinkErr)
+		updates["next_attempt_at"] = time.Now().Add(retryDelay(event.Attempts))
+		updates["last_error"] = truncate(sinkErr.Error(), lastErrorLength)
+	}
+	if err := r.db.Model(event).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record outbox event: %w", err)
+	}
+	return nil
+}
+
+// writeOutbox records task changes in the outbox as part of the transaction that made them
+func writeOutbox(tx *gorm.DB, changes []taskChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := time.Now()
+	events := make([]models.OutboxEvent, 0, len(changes))
+	for _, change := range changes {
+		payload, err := json.Marshal(models.EventPayload{
+			EventID:    change.change.ID,
+			Type:       change.eventType,
+			OccurredAt: change.change.CreatedAt,
+			Data:       change.change,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode outbox event: %w", err)
+		}
+		events = append(events, models.OutboxEvent{
+			IdempotencyKey: "task-event-" + strconv.FormatUint(uint64(change.change.ID), 10),
+			Type:           change.eventType,
+			Payload:        models.JSONDocument(payload),
+			Audience:       change.audience,
+			NextAttemptAt:  now,
+		})
+	}
+	if err := tx.Create(&events).Error; err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
+
+// retryDelay returns how long to wait after the nth failed attempt
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
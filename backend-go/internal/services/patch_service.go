@@ -0,0 +1,96 @@
+package services
+
+import (
+	"sort"
+
+	"task-manager-backend/internal/models"
+)
+
+// ReplaceTask sets every patchable field of a task to the values in doc, as produced by
+// applying a PATCH to models.NewTaskDocument. Unlike UpdateTask, null values clear fields.
+// The task must still be at expectedVersion.
+func (s *TaskService) ReplaceTask(userID, taskID uint, doc *models.TaskDocument, expectedVersion uint) (*models.Task, error) {
+	task, err := s.getEditableTask(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireVersion(task, &expectedVersion); err != nil {
+		return nil, err
+	}
+
+	// Only replace tags that actually changed, so tags from teammates in a shared project
+	// are not swapped for the acting user's tags of the same name
+	var tags *[]string
+	if err := loadTaskTags(s.db, task); err != nil {
+		return nil, err
+	}
+	if !sameTagNames(task.Tags, doc.Tags) {
+		tags = &doc.Tags
+	}
+
+	wasCompleted := task.IsCompleted()
+	before, err := s.snapshotForUpdate(task, tags != nil)
+	if err != nil {
+		return nil, err
+	}
+	task.Tags = nil
+
+	task.Title = doc.Title
+	task.Description = doc.Description
+	task.Status = doc.Status
+	task.Priority = doc.Priority
+	task.DueDate = doc.DueDate
+	task.AutoComplete = doc.AutoComplete
+	task.Position = doc.Position
+	rule := ""
+	if doc.Recurrence != nil {
+		rule = *doc.Recurrence
+	}
+	if err := applyRecurrence(task, rule); err != nil {
+		return nil, err
+	}
+	moved := false
+	if projectID := projectIDOrZero(doc.ProjectID); projectID != projectIDOrZero(task.ProjectID) {
+		if moved, err = s.moveToProject(userID, task, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.saveTaskUpdate(userID, task, before, wasCompleted, moved, tags); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func sameTagNames(tags []models.Tag, names []string) bool {
+	current := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		current = append(current, models.NormalizeTagName(tag.Name))
+	}
+	wanted := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = models.NormalizeTagName(name); name != "" && !seen[name] {
+			seen[name] = true
+			wanted = append(wanted, name)
+		}
+	}
+	if len(current) != len(wanted) {
+		return false
+	}
+	sort.Strings(current)
+	sort.Strings(wanted)
+	for i := range current {
+		if current[i] != wanted[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func projectIDOrZero(projectID *uint) uint {
+	if projectID == nil {
+		return 0
+	}
+	return *projectID
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ProjectService struct {
+	db    *gorm.DB
+	tasks *TaskService
+}
+
+func NewProjectService(db *gorm.DB, tasks *TaskService) *ProjectService {
+	return &ProjectService{db: db, tasks: tasks}
+}
+
+// CreateProject creates a project with the user as its owner
+func (s *ProjectService) CreateProject(userID uint, req *models.CreateProjectRequest) (*models.Project, error) {
+	project := &models.Project{
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerID:     userID,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		owner := models.ProjectMember{ProjectID: project.ID, UserID: userID, Role: models.RoleOwner}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+		project.Members = []models.ProjectMember{owner}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return project, nil
+}
+
+// GetProjects returns the projects the user is a member of
+func (s *ProjectService) GetProjects(userID uint) ([]models.Project, error) {
+	var projects []models.Project
+	err := s.db.
+		Where("id IN (?)", s.db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", userID)).
+		Order("name ASC").
+		Find(&projects).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	return projects, nil
+}
+
+// GetProject retrieves a project with its members if the user is a member
+func (s *ProjectService) GetProject(userID, projectID uint) (*models.Project, error) {
+	if _, err := projectRole(s.db, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	var project models.Project
+	err := s.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).Preload("Members.User").First(&project, projectID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("project not found")
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return &project, nil
+}
+
+// UpdateProject renames a project or changes its description. Only owners may do this.
+func (s *ProjectService) UpdateProject(userID, projectID uint, req *models.UpdateProjectRequest) (*models.Project, error) {
+	if err := s.requireManager(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	project, err := s.GetProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		project.Name = *req.Name
+	}
+	if req.Description != nil {
+		project.Description = req.Description
+	}
+
+	if err := s.db.Omit("Members").Save(project).Error; err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+	return project, nil
+}
+
+// DeleteProject deletes a project together with its tasks and their comments (soft delete)
+// and its memberships.
+// Only owners may do this.
+func (s *ProjectService) DeleteProject(userID, projectID uint) error {
+	if err := s.requireManager(userID, projectID); err != nil {
+		return err
+	}
+
+	err := s.tasks.transaction(func(tx *gorm.DB) error {
+		var tasks []models.Task
+		if err := tx.Where("project_id = ?", projectID).Find(&tasks).Error; err != nil {
+			return err
+		}
+		var members []uint
+		if err := tx.Model(&models.ProjectMember{}).Where("project_id = ?", projectID).Pluck("user_id", &members).Error; err != nil {
+			return err
+		}
+		for i := range tasks {
+			if err := recordTaskEvent(tx, userID, tasks[i].ID, models.OperationDelete, diffSnapshots(snapshotTask(&tasks[i]), nil)); err != nil {
+				return err
+			}
+			// The memberships are gone by the time the events are published
+			noteTaskAudience(tx, tasks[i].ID, members)
+		}
+		if err := deleteTaskComments(tx, "project_id = ?", projectID); err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&models.Task{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&models.ProjectMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Project{}, projectID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	return nil
+}
+
+// AddMember adds the user with the given email to a project. Only owners may do this.
+func (s *ProjectService) AddMember(userID, projectID uint, req *models.AddMemberRequest) (*models.ProjectMember, error) {
+	if err := s.requireManager(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err := s.db.Where("email = ?", models.NormalizeEmail(req.Email)).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if _, err := projectRole(s.db, user.ID, projectID); err == nil {
+		return nil, errors.New("user is already a member")
+	} else if err.Error() != "project not found" {
+		return nil, err
+	}
+
+	member := &models.ProjectMember{ProjectID: projectID, UserID: user.ID, Role: req.Role}
+	if err := s.db.Create(member).Error; err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	member.User = &user
+	return member, nil
+}
+
+// UpdateMemberRole changes a member's role. Only owners may do this, and the last owner
+// cannot be demoted.
+func (s *ProjectService) UpdateMemberRole(userID, projectID, memberUserID uint, req *models.UpdateMemberRequest) (*models.ProjectMember, error) {
+	if err := s.requireManager(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.getMember(projectID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == models.RoleOwner && req.Role != models.RoleOwner {
+		if err := s.requireAnotherOwner(projectID, memberUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = req.Role
+	if err := s.db.Save(member).Error; err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	return member, nil
+}
+
+// RemoveMember removes a member from a project and unassigns their tasks in it. Owners may
+// remove anyone and any member may leave, but the last owner cannot be removed.
+func (s *ProjectService) RemoveMember(userID, projectID, memberUserID uint) error {
+	if userID != memberUserID {
+		if err := s.requireManager(userID, projectID); err != nil {
+			return err
+		}
+	}
+
+	member, err := s.getMember(projectID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.RoleOwner {
+		if err := s.requireAnotherOwner(projectID, memberUserID); err != nil {
+			return err
+		}
+	}
+
+	err = s.tasks.transaction(func(tx *gorm.DB) error {
+		if err := unassignMember(tx, userID, projectID, memberUserID); err != nil {
+			return err
+		}
+		return tx.Delete(member).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+func (s *ProjectService) requireManager(userID, projectID uint) error {
+	role, err := projectRole(s.db, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if !role.CanManage() {
+		return errors.New("insufficient permissions")
+	}
+	return nil
+}
+
+func (s *ProjectService) getMember(projectID, memberUserID uint) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	err := s.db.Where("project_id = ? AND user_id = ?", projectID, memberUserID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("member not found")
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	return &member, nil
+}
+
+func (s *ProjectService) requireAnotherOwner(projectID, memberUserID uint) error {
+	var owners int64
+	err := s.db.Model(&models.ProjectMember{}).
+		Where("project_id = ? AND role = ? AND user_id <> ?", projectID, models.RoleOwner, memberUserID).
+		Count(&owners).Error
+	if err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners == 0 {
+		return errors.New("project must keep an owner")
+	}
+	return nil
+}
+
+// moveToProject points a task at another project, or makes it private when projectID is 0.
+// The user must be able to edit tasks in the target project, and only a task's creator may
+// make it private. It reports whether the task actually moved.
+func (s *TaskService) moveToProject(userID uint, task *models.Task, projectID uint) (bool, error) {
+	if task.IsSubtask() {
+		return false, errors.New("subtasks follow their parent's project")
+	}
+
+	if projectID == 0 {
+		if task.ProjectID == nil {
+			return false, nil
+		}
+		if task.UserID != userID {
+			return false, errors.New("insufficient permissions")
+		}
+		task.ProjectID = nil
+		return true, nil
+	}
+
+	if task.ProjectID != nil && *task.ProjectID == projectID {
+		return false, nil
+	}
+	if err := requireProjectEditor(s.db, userID, projectID); err != nil {
+		return false, err
+	}
+	task.ProjectID = &projectID
+	return true, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TaskPublisher is told about task changes once they are committed
+type TaskPublisher interface {
+	Publish(userIDs []uint, eventType string, data interface{})
+}
+
+// SetPublisher makes the service publish every task event it records
+func (s *TaskService) SetPublisher(publisher TaskPublisher) {
+	s.publisher = publisher
+}
+
+type recordedEventsKey struct{}
+
+// recordedEvents collects the task events written inside a transaction, along with the
+// tasks purged by it and the audiences of tasks whose project it deletes, which are gone
+// by the time the events are loaded
+type recordedEvents struct {
+	events    []models.TaskEvent
+	purged    map[uint]models.Task
+	audiences map[uint][]uint
+}
+
+// noteRecordedEvent remembers an event for publishing if tx belongs to a publishing transaction
+func noteRecordedEvent(tx *gorm.DB, event *models.TaskEvent) {
+	if recorded, ok := tx.Statement.Context.Value(recordedEventsKey{}).(*recordedEvents); ok {
+		recorded.events = append(recorded.events, *event)
+	}
+}
+
+// notePurgedTask remembers a task that tx is about to delete permanently, so that its
+// events can still be published
+func notePurgedTask(tx *gorm.DB, task *models.Task) {
+	if recorded, ok := tx.Statement.Context.Value(recordedEventsKey{}).(*recordedEvents); ok {
+		if recorded.purged == nil {
+			recorded.purged = map[uint]models.Task{}
+		}
+		recorded.purged[task.ID] = *task
+	}
+}
+
+// noteTaskAudience fixes the users a task's events are sent to, for a task whose project
+// memberships tx is about to remove
+func noteTaskAudience(tx *gorm.DB, taskID uint, audience []uint) {
+	if recorded, ok := tx.Statement.Context.Value(recordedEventsKey{}).(*recordedEvents); ok {
+		if recorded.audiences == nil {
+			recorded.audiences = map[uint][]uint{}
+		}
+		recorded.audiences[taskID] = audience
+	}
+}
+
+// taskChange is a committed task event ready to be sent to the users who can see the task
+type taskChange struct {
+	eventType string
+	audience  []uint
+	change    models.TaskChange
+}
+
+// SetOutboxRelay makes the service wake the relay whenever it commits outbox events
+func (s *TaskService) SetOutboxRelay(relay *OutboxRelay) {
+	s.relay = relay
+}
+
+// transaction runs fn in a transaction. The task events it records are written to the
+// outbox in the same transaction and published once it commits, and the tasks showing the
+// changed ones get new versions. Inside an enclosing transaction
+// the events are left for that one, and those of a failed nested transaction are discarded
+// with its savepoint.
+func (s *TaskService) transaction(fn func(tx *gorm.DB) error) error {
+	if recorded, ok := s.db.Statement.Context.Value(recordedEventsKey{}).(*recordedEvents); ok {
+		mark := len(recorded.events)
+		err := s.db.Transaction(fn)
+		if err != nil {
+			recorded.events = recorded.events[:mark]
+		}
+		return err
+	}
+
+	recorded := &recordedEvents{}
+	ctx := s.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var changes []taskChange
+	err := s.db.WithContext(context.WithValue(ctx, recordedEventsKey{}, recorded)).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := touchRelatedTasks(tx, recorded); err != nil {
+			return err
+		}
+		var err error
+		if changes, err = loadTaskChanges(tx, recorded); err != nil {
+			return err
+		}
+		if err := rescheduleReminders(tx, changes); err != nil {
+			return err
+		}
+		return writeOutbox(tx, changes)
+	})
+	if err == nil && len(changes) > 0 {
+		s.publish(changes)
+		if s.relay != nil {
+			s.relay.Notify()
+		}
+	}
+	return err
+}
+
+// publish sends committed task changes to the users who can see the task
+func (s *TaskService) publish(changes []taskChange) {
+	if s.publisher == nil {
+		return
+	}
+	for _, change := range changes {
+		s.publisher.Publish(change.audience, change.eventType, change.change)
+	}
+}
+
+// loadTaskChanges pairs task events with the task as it is after them and the users who
+// can see it. Purged tasks are taken as they were before they were deleted, and noted
+// audiences are used as they are.
+func loadTaskChanges(db *gorm.DB, recorded *recordedEvents) ([]taskChange, error) {
+	purged := recorded.purged
+	changes := make([]taskChange, 0, len(recorded.events))
+	for _, event := range recorded.events {
+		task, ok := purged[event.TaskID]
+		if !ok {
+			if err := db.Unscoped().First(&task, event.TaskID).Error; err != nil {
+				return nil, fmt.Errorf("failed to load task %d: %w", event.TaskID, err)
+			}
+		}
+		audience, ok := recorded.audiences[task.ID]
+		if !ok {
+			var err error
+			if audience, err = taskAudience(db, &task); err != nil {
+				return nil, fmt.Errorf("failed to resolve the audience of task %d: %w", task.ID, err)
+			}
+		}
+
+		change := models.TaskChange{TaskEvent: event}
+		if _, gone := purged[task.ID]; !gone && !task.DeletedAt.Valid {
+			if err := loadTaskTags(db, &task); err != nil {
+				return nil, fmt.Errorf("failed to load tags of task %d: %w", task.ID, err)
+			}
+			tasks := []models.Task{task}
+			if err := markBlocked(db, tasks); err != nil {
+				return nil, err
+			}
+			change.Task = &tasks[0]
+		}
+		changes = append(changes, taskChange{eventType: event.Operation.EventType(), audience: audience, change: change})
+	}
+	return changes, nil
+}
+
+// taskAudience returns the users who can see a task: its creator for a private task, or
+// the members of its project
+func taskAudience(db *gorm.DB, task *models.Task) ([]uint, error) {
+	if task.ProjectID == nil {
+		return []uint{task.UserID}, nil
+	}
+	var userIDs []uint
+	err := db.Model(&models.ProjectMember{}).Where("project_id = ?", *task.ProjectID).Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/recurrence"
+
+	"gorm.io/gorm"
+)
+
+// PreviewOccurrences returns the next count occurrences of a recurring task after its current due date
+func (s *TaskService) PreviewOccurrences(userID, taskID uint, count int) ([]recurrence.Occurrence, error) {
+	task, err := s.GetTaskByID(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsRecurring() || task.DueDate == nil || task.RecurrenceStart == nil {
+		return nil, errors.New("task is not recurring")
+	}
+
+	rule, err := recurrence.Parse(*task.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+
+	occurrences := rule.After(*task.RecurrenceStart, *task.DueDate, count)
+	if occurrences == nil {
+		occurrences = []recurrence.Occurrence{}
+	}
+	return occurrences, nil
+}
+
+// applyRecurrence validates rule and stores it on the task, anchoring the series at the
+// task's due date. An empty rule stops the task from recurring.
+func applyRecurrence(task *models.Task, rule string) error {
+	if rule == "" {
+		task.Recurrence = nil
+		task.RecurrenceStart = nil
+		task.RecurrenceIndex = 0
+		return nil
+	}
+
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		return fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+	if task.DueDate == nil {
+		return errors.New("recurring tasks require a due date")
+	}
+
+	canonical := parsed.String()
+	if task.IsRecurring() && *task.Recurrence == canonical {
+		return nil
+	}
+
+	start := *task.DueDate
+	task.Recurrence = &canonical
+	task.RecurrenceStart = &start
+	task.RecurrenceIndex = 1
+	return nil
+}
+
+// spawnNextOccurrence creates the next occurrence of a completed recurring task with its
+// due date moved forward. Subtasks are copied as pending checklist items and reminders are
+// carried over. Each task spawns at most one successor, so completing a task twice does not
+// duplicate the series. The new tasks are recorded as created by actorID.
+func spawnNextOccurrence(tx *gorm.DB, actorID uint, task *models.Task) error {
+	if !task.IsRecurring() || task.NextOccurrenceID != nil || task.DueDate == nil || task.RecurrenceStart == nil {
+		return nil
+	}
+
+	rule, err := recurrence.Parse(*task.Recurrence)
+	if err != nil {
+		return fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+
+	occurrence, ok := rule.Next(*task.RecurrenceStart, *task.DueDate)
+	if !ok {
+		return nil
+	}
+
+	dueDate := occurrence.Time
+	start := *task.RecurrenceStart
+	next := &models.Task{
+		Title:           task.Title,
+		Description:     task.Description,
+		Status:          models.StatusPending,
+		Priority:        task.Priority,
+		DueDate:         &dueDate,
+		UserID:          task.UserID,
+		ProjectID:       task.ProjectID,
+		AssigneeID:      task.AssigneeID,
+		AssignedByID:    task.AssignedByID,
+		AssignedAt:      task.AssignedAt,
+		ParentID:        task.ParentID,
+		AutoComplete:    task.AutoComplete,
+		Position:        task.Position,
+		Recurrence:      task.Recurrence,
+		RecurrenceStart: &start,
+		RecurrenceIndex: occurrence.Index,
+	}
+	if err := tx.Create(next).Error; err != nil {
+		return err
+	}
+
+	var tags []models.Tag
+	if err := tx.Model(task).Association("Tags").Find(&tags); err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		if err := tx.Model(next).Association("Tags").Append(tags); err != nil {
+			return err
+		}
+	}
+	if err := recordTaskEvent(tx, actorID, next.ID, models.OperationCreate, diffSnapshots(nil, snapshotTask(next).withTags(tags))); err != nil {
+		return err
+	}
+
+	var reminders []models.TaskReminder
+	if err := tx.Where("task_id = ?", task.ID).Find(&reminders).Error; err != nil {
+		return err
+	}
+	for _, reminder := range reminders {
+		carried := &models.TaskReminder{TaskID: next.ID, UserID: reminder.UserID, OffsetMinutes: reminder.OffsetMinutes}
+		carried.Schedule(next.DueDate)
+		if err := tx.Create(carried).Error; err != nil {
+			return err
+		}
+	}
+
+	var subtasks []models.Task
+	if err := tx.Where("parent_id = ?", task.ID).Order("created_at ASC, id ASC").Find(&subtasks).Error; err != nil {
+		return err
+	}
+	for _, subtask := range subtasks {
+		copied := &models.Task{
+			Title:       subtask.Title,
+			Description: subtask.Description,
+			Status:      models.StatusPending,
+			Priority:    subtask.Priority,
+			UserID:      subtask.UserID,
+			ProjectID:   next.ProjectID,
+			ParentID:    &next.ID,
+		}
+		if err := tx.Create(copied).Error; err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, actorID, copied.ID, models.OperationCreate, diffSnapshots(nil, snapshotTask(copied))); err != nil {
+			return err
+		}
+	}
+
+	if err := updateTaskColumns(tx, task, map[string]interface{}{"next_occurrence_id": next.ID}); err != nil {
+		return err
+	}
+	task.NextOccurrenceID = &next.ID
+	return nil
+}
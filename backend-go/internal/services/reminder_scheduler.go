@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/notify"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// reminderLease is how long a claimed reminder or digest stays with the scheduler that
+	// claimed it. If that scheduler dies, another one sends it once the lease runs out.
+	reminderLease = 5 * time.Minute
+	// reminderRetry is how long a reminder or digest that a channel failed to deliver waits
+	// before it is sent again
+	reminderRetry = time.Minute
+	// reminderGrace drops reminders of tasks that have been overdue for longer, such as
+	// those of a task reopened long after it was due
+	reminderGrace = time.Hour
+	// digestTaskLimit caps the tasks listed in one digest
+	digestTaskLimit = 50
+	// digestTimeLayout formats due dates in reminders and digests
+	digestTimeLayout = "Mon, 02 Jan 2006 15:04 MST"
+)
+
+// ReminderScheduler sends due task reminders and daily digests through the notifiers of the
+// channels each user chose. Several schedulers can run against one database: each reminder
+// and digest is claimed under a row lock before it is sent.
+type ReminderScheduler struct {
+	db        *gorm.DB
+	notifiers map[string]notify.Notifier
+}
+
+func NewReminderScheduler(db *gorm.DB) *ReminderScheduler {
+	return &ReminderScheduler{db: db, notifiers: map[string]notify.Notifier{}}
+}
+
+// AddNotifier registers the notifier of a channel such as models.ChannelEmail. Users who
+// chose a channel without a notifier get nothing through it.
+func (s *ReminderScheduler) AddNotifier(channel string, notifier notify.Notifier) {
+	s.notifiers[channel] = notifier
+}
+
+// SendDueReminders sends up to limit reminders that are due at now and returns how many it
+// claimed
+func (s *ReminderScheduler) SendDueReminders(ctx context.Context, now time.Time, limit int) (int, error) {
+	var reminders []models.TaskReminder
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Scopes(skipLocked("task_reminders")).
+			Joins("JOIN tasks ON tasks.id = task_reminders.task_id AND tasks.deleted_at IS NULL AND tasks.status = ?", models.StatusPending).
+			Where("task_reminders.sent_at IS NULL AND task_reminders.remind_at <= ?", now).
+			Where("(task_reminders.locked_until IS NULL OR task_reminders.locked_until < ?)", now).
+			Order("task_reminders.remind_at ASC, task_reminders.id ASC").
+			Limit(limit).
+			Find(&reminders).Error
+		if err != nil || len(reminders) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(reminders))
+		for _, reminder := range reminders {
+			ids = append(ids, reminder.ID)
+		}
+		return tx.Model(&models.TaskReminder{}).Where("id IN ?", ids).Update("locked_until", now.Add(reminderLease)).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim reminders: %w", err)
+	}
+
+	for i := range reminders {
+		if err := s.sendReminder(ctx, now, &reminders[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(reminders), nil
+}
+
+// sendReminder sends a claimed reminder and marks it sent. Reminders the user can no longer
+// see, or of tasks long overdue, are marked sent without a message. When a channel fails the
+// reminder stays unsent and is tried again after reminderRetry; channels that delivered it
+// already keep it once by its key.
+func (s *ReminderScheduler) sendReminder(ctx context.Context, now time.Time, reminder *models.TaskReminder) error {
+	var task models.Task
+	err := s.db.WithContext(ctx).Scopes(visibleTo(reminder.UserID)).Where("tasks.id = ?", reminder.TaskID).First(&task).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return fmt.Errorf("failed to get task: %w", err)
+	case task.DueDate != nil && now.Sub(*task.DueDate) <= reminderGrace:
+		settings, err := loadNotificationSettings(s.db, reminder.UserID)
+		if err != nil {
+			return err
+		}
+		due := task.DueDate.In(settingsLocation(settings))
+		subject := fmt.Sprintf("%q is due %s", task.Title, due.Format(digestTimeLayout))
+		switch {
+		case reminder.OffsetMinutes == 0:
+			subject = fmt.Sprintf("%q is due now", task.Title)
+		case task.IsOverdue():
+			subject = fmt.Sprintf("%q is overdue", task.Title)
+		}
+		err = s.dispatch(ctx, settings, &notify.Message{
+			Key:     fmt.Sprintf("reminder-%d-%d", reminder.ID, reminder.RemindAt.Unix()),
+			Type:    notify.TypeReminder,
+			Subject: subject,
+			Body:    fmt.Sprintf("%s\nDue %s", task.Title, due.Format(digestTimeLayout)),
+			TaskIDs: []uint{task.ID},
+			At:      now,
+		})
+		if err != nil {
+			log.Printf("Failed to send reminder %d, retrying: %v", reminder.ID, err)
+			err = s.db.WithContext(ctx).Model(reminder).Update("locked_until", now.Add(reminderRetry)).Error
+			if err != nil {
+				return fmt.Errorf("failed to release reminder: %w", err)
+			}
+			return nil
+		}
+	}
+
+	err = s.db.WithContext(ctx).Model(reminder).Updates(map[string]interface{}{"sent_at": now, "locked_until": nil}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark reminder sent: %w", err)
+	}
+	return nil
+}
+
+// SendDigests sends the daily digest to every user whose digest hour has come at now and
+// who has not had one that day, and returns how many it claimed
+func (s *ReminderScheduler) SendDigests(ctx context.Context, now time.Time) (int, error) {
+	var due []models.NotificationSettings
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var candidates []models.NotificationSettings
+		err := tx.Scopes(skipLocked("notification_settings")).
+			Where("digest_enabled = ?", true).
+			Where("(locked_until IS NULL OR locked_until < ?)", now).
+			Order("user_id ASC").
+			Find(&candidates).Error
+		if err != nil {
+			return err
+		}
+		// The digest hour is in each user's time zone, so it is checked here rather than in SQL
+		var userIDs []uint
+		for _, settings := range candidates {
+			local := now.In(settingsLocation(&settings))
+			if local.Hour() >= settings.DigestHour && settings.LastDigestOn != local.Format(time.DateOnly) {
+				due = append(due, settings)
+				userIDs = append(userIDs, settings.UserID)
+			}
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+		return tx.Model(&models.NotificationSettings{}).Where("user_id IN ?", userIDs).Update("locked_until", now.Add(reminderLease)).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim digests: %w", err)
+	}
+
+	for i := range due {
+		if err := s.sendDigest(ctx, now, &due[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
+// sendDigest sends a user the pending tasks of theirs that are overdue or due by the end of
+// their day, then records the digest as sent for that day. Nothing is sent when no task is due.
+// A digest that a channel failed to deliver is tried again after reminderRetry.
+func (s *ReminderScheduler) sendDigest(ctx context.Context, now time.Time, settings *models.NotificationSettings) error {
+	loc := settingsLocation(settings)
+	local := now.In(loc)
+	endOfDay := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+
+	// A user's tasks are those assigned to them and those they created and left unassigned
+	var tasks []models.Task
+	err := s.db.WithContext(ctx).Scopes(visibleTo(settings.UserID)).
+		Where("(tasks.assignee_id = ? OR (tasks.assignee_id IS NULL AND tasks.user_id = ?))", settings.UserID, settings.UserID).
+		Where("tasks.status = ? AND tasks.due_date IS NOT NULL AND tasks.due_date < ?", models.StatusPending, endOfDay).
+		Order("tasks.due_date ASC, tasks.id ASC").
+		Limit(digestTaskLimit).
+		Find(&tasks).Error
+	if err != nil {
+		return fmt.Errorf("failed to get digest tasks: %w", err)
+	}
+
+	if len(tasks) > 0 {
+		var overdue, today []string
+		taskIDs := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			line := fmt.Sprintf("- %s (due %s)", task.Title, task.DueDate.In(loc).Format(digestTimeLayout))
+			if task.IsOverdue() {
+				overdue = append(overdue, line)
+			} else {
+				today = append(today, line)
+			}
+			taskIDs = append(taskIDs, task.ID)
+		}
+		var body strings.Builder
+		if len(overdue) > 0 {
+			body.WriteString("Overdue:\n" + strings.Join(overdue, "\n") + "\n")
+		}
+		if len(today) > 0 {
+			if body.Len() > 0 {
+				body.WriteString("\n")
+			}
+			body.WriteString("Due today:\n" + strings.Join(today, "\n") + "\n")
+		}
+		err := s.dispatch(ctx, settings, &notify.Message{
+			Key:     fmt.Sprintf("digest-%d-%s", settings.UserID, local.Format(time.DateOnly)),
+			Type:    notify.TypeDigest,
+			Subject: fmt.Sprintf("Your tasks for %s: %d overdue, %d due today", local.Format("Mon, 02 Jan"), len(overdue), len(today)),
+			Body:    body.String(),
+			TaskIDs: taskIDs,
+			At:      now,
+		})
+		if err != nil {
+			log.Printf("Failed to send the digest of user %d, retrying: %v", settings.UserID, err)
+			err = s.db.WithContext(ctx).Model(&models.NotificationSettings{}).Where("user_id = ?", settings.UserID).
+				Update("locked_until", now.Add(reminderRetry)).Error
+			if err != nil {
+				return fmt.Errorf("failed to release digest: %w", err)
+			}
+			return nil
+		}
+	}
+
+	err = s.db.WithContext(ctx).Model(&models.NotificationSettings{}).Where("user_id = ?", settings.UserID).
+		Updates(map[string]interface{}{"last_digest_on": local.Format(time.DateOnly), "locked_until": nil}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark digest sent: %w", err)
+	}
+	return nil
+}
+
+// dispatch hands a message to the notifier of every channel the user chose. A channel that
+// fails does not hold up the others; the failures are returned together.
+func (s *ReminderScheduler) dispatch(ctx context.Context, settings *models.NotificationSettings, msg *notify.Message) error {
+	msg.UserID = settings.UserID
+	if settings.Channels.Includes(models.ChannelEmail) {
+		var user models.User
+		if err := s.db.WithContext(ctx).First(&user, settings.UserID).Error; err != nil {
+			return fmt.Errorf("failed to get the email address of user %d: %w", settings.UserID, err)
+		}
+		msg.Email = user.Email
+	}
+
+	var errs []error
+	for _, channel := range settings.Channels {
+		notifier, ok := s.notifiers[channel]
+		if !ok {
+			continue
+		}
+		if err := notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// settingsLocation returns the user's time zone, falling back to UTC
+func settingsLocation(settings *models.NotificationSettings) *time.Location {
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// skipLocked locks the rows a query selects from table and skips rows another transaction
+// holds, so that concurrent schedulers claim different rows. SQLite has no row locks but
+// lets only one transaction write at a time, so it needs none.
+func skipLocked(table string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if db.Dialector.Name() != "postgres" {
+			return db
+		}
+		return db.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: table}, Options: "SKIP LOCKED"})
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GetReminders lists the reminders a user set on a task, earliest first
+func (s *TaskService) GetReminders(userID, taskID uint) ([]models.TaskReminder, error) {
+	if err := requireVisibleTask(s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	reminders := []models.TaskReminder{}
+	err := s.db.Where("task_id = ? AND user_id = ?", taskID, userID).Order("offset_minutes DESC").Find(&reminders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// AddReminder reminds the user of a task offsetMinutes before it is due. Any user who can
+// see the task can set reminders for themselves.
+func (s *TaskService) AddReminder(userID, taskID uint, offsetMinutes int) (*models.TaskReminder, error) {
+	var task models.Task
+	err := s.db.Scopes(visibleTo(userID)).Where("tasks.id = ?", taskID).First(&task).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("task not found")
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	var existing int64
+	err = s.db.Model(&models.TaskReminder{}).
+		Where("task_id = ? AND user_id = ? AND offset_minutes = ?", taskID, userID, offsetMinutes).
+		Count(&existing).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reminders: %w", err)
+	}
+	if existing > 0 {
+		return nil, errors.New("reminder already exists")
+	}
+
+	reminder := &models.TaskReminder{TaskID: taskID, UserID: userID, OffsetMinutes: offsetMinutes}
+	reminder.Schedule(task.DueDate)
+	if err := s.db.Create(reminder).Error; err != nil {
+		return nil, fmt.Errorf("failed to create reminder: %w", err)
+	}
+	return reminder, nil
+}
+
+// RemoveReminder deletes one of the user's reminders on a task
+func (s *TaskService) RemoveReminder(userID, taskID, reminderID uint) error {
+	if err := requireVisibleTask(s.db, userID, taskID); err != nil {
+		return err
+	}
+
+	result := s.db.Where("id = ? AND task_id = ? AND user_id = ?", reminderID, taskID, userID).Delete(&models.TaskReminder{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete reminder: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("reminder not found")
+	}
+	return nil
+}
+
+// rescheduleReminders moves the reminders of tasks whose due date changed. A moved reminder
+// is sent again, even if it was sent for the old due date.
+func rescheduleReminders(tx *gorm.DB, changes []taskChange) error {
+	for _, change := range changes {
+		if _, ok := change.change.Changes["dueDate"]; !ok || change.change.Task == nil {
+			continue
+		}
+		var reminders []models.TaskReminder
+		if err := tx.Where("task_id = ?", change.change.TaskID).Find(&reminders).Error; err != nil {
+			return fmt.Errorf("failed to get reminders: %w", err)
+		}
+		for i := range reminders {
+			reminders[i].Schedule(change.change.Task.DueDate)
+			err := tx.Model(&reminders[i]).Updates(map[string]interface{}{
+				"remind_at":    reminders[i].RemindAt,
+				"sent_at":      nil,
+				"locked_until": nil,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to reschedule reminder: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CreateSubtask creates a subtask under the given parent task
+func (s *TaskService) CreateSubtask(userID, parentID uint, req *models.CreateTaskRequest) (*models.Task, error) {
+	parent, err := s.getEditableTask(userID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.IsSubtask() {
+		return nil, errors.New("subtasks cannot be nested")
+	}
+
+	task, err := newTaskFromRequest(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	task.ParentID = &parent.ID
+	task.ProjectID = parent.ProjectID
+
+	err = s.transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		after := snapshotTask(task)
+		if len(req.Tags) > 0 {
+			if err := replaceTaskTags(tx, userID, task, req.Tags); err != nil {
+				return err
+			}
+			after.withTags(task.Tags)
+		}
+		if err := recordTaskEvent(tx, userID, task.ID, models.OperationCreate, diffSnapshots(nil, after)); err != nil {
+			return err
+		}
+		return syncParentStatus(tx, userID, parent.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subtask: %w", err)
+	}
+
+	return task, nil
+}
+
+// GetSubtasks retrieves the subtasks of a parent task, oldest first
+func (s *TaskService) GetSubtasks(userID, parentID uint) ([]models.Task, error) {
+	if _, err := s.GetTaskByID(userID, parentID); err != nil {
+		return nil, err
+	}
+
+	var subtasks []models.Task
+	if err := s.db.Where("parent_id = ?", parentID).Order("created_at ASC, id ASC").Find(&subtasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+	if err := markBlocked(s.db, subtasks); err != nil {
+		return nil, err
+	}
+	return subtasks, nil
+}
+
+// GetSubtask retrieves a single subtask and verifies it belongs to the parent task
+func (s *TaskService) GetSubtask(userID, parentID, subtaskID uint) (*models.Task, error) {
+	task, err := s.GetTaskByID(userID, subtaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.ParentID == nil || *task.ParentID != parentID {
+		return nil, errors.New("task not found")
+	}
+	return task, nil
+}
+
+// GetTaskWithSubtasks retrieves a task by ID with its subtasks loaded
+func (s *TaskService) GetTaskWithSubtasks(userID, taskID uint) (*models.Task, error) {
+	task, err := s.GetTaskByID(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadTaskTags(s.db, task); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	if err := s.db.Where("parent_id = ?", task.ID).Order("created_at ASC, id ASC").Preload("Tags", orderTagsByName).Find(&task.Subtasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+	if err := markBlocked(s.db, task.Subtasks); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// syncTaskHierarchy keeps auto-completing parents in step after a task changed: the
+// task's own parent, or the task itself when it is a parent
+func syncTaskHierarchy(tx *gorm.DB, actorID uint, task *models.Task) error {
+	if task.ParentID != nil {
+		return syncParentStatus(tx, actorID, *task.ParentID)
+	}
+	if task.AutoComplete {
+		if err := syncParentStatus(tx, actorID, task.ID); err != nil {
+			return err
+		}
+		return tx.First(task, task.ID).Error
+	}
+	return nil
+}
+
+// syncParentStatus completes an auto-completing parent once every subtask is completed,
+// and reopens it when a pending subtask appears again. The change is recorded as done by actorID.
+func syncParentStatus(tx *gorm.DB, actorID, parentID uint) error {
+	var parent models.Task
+	if err := tx.First(&parent, parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !parent.AutoComplete {
+		return nil
+	}
+
+	var total, pending int64
+	if err := tx.Model(&models.Task{}).Where("parent_id = ?", parentID).Count(&total).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Task{}).Where("parent_id = ? AND status = ?", parentID, models.StatusPending).Count(&pending).Error; err != nil {
+		return err
+	}
+	if total == 0 {
+		return nil
+	}
+
+	status := models.StatusPending
+	if pending == 0 {
+		status = models.StatusCompleted
+	}
+	if parent.Status == status {
+		return nil
+	}
+	before := snapshotTask(&parent)
+	if err := updateTaskColumns(tx, &parent, map[string]interface{}{"status": status}); err != nil {
+		return err
+	}
+	parent.Status = status
+	operation := models.OperationReopen
+	if status == models.StatusCompleted {
+		operation = models.OperationComplete
+	}
+	if err := recordTaskEvent(tx, actorID, parent.ID, operation, diffSnapshots(before, snapshotTask(&parent))); err != nil {
+		return err
+	}
+	if status == models.StatusCompleted {
+		return spawnNextOccurrence(tx, actorID, &parent)
+	}
+	return nil
+}
+
+// subtaskProgress computes subtask totals and the average completion percentage of
+// top-level tasks visible to a user. A completed task counts fully; a pending task with
+// subtasks counts the share of its completed subtasks.
+func (s *TaskService) subtaskProgress(userID uint) (subtasks, subtasksCompleted int64, progress float64, err error) {
+	var rows []struct {
+		ID       uint
+		ParentID *uint
+		Status   models.TaskStatus
+	}
+	if err = s.db.Model(&models.Task{}).Select("id, parent_id, status").Scopes(visibleTo(userID)).Find(&rows).Error; err != nil {
+		return 0, 0, 0, err
+	}
+
+	type counts struct{ done, total int }
+	children := make(map[uint]*counts)
+	for _, row := range rows {
+		if row.ParentID == nil {
+			continue
+		}
+		c, ok := children[*row.ParentID]
+		if !ok {
+			c = &counts{}
+			children[*row.ParentID] = c
+		}
+		c.total++
+		subtasks++
+		if row.Status == models.StatusCompleted {
+			c.done++
+			subtasksCompleted++
+		}
+	}
+
+	var sum float64
+	var topLevel int
+	for _, row := range rows {
+		if row.ParentID != nil {
+			continue
+		}
+		topLevel++
+		if row.Status == models.StatusCompleted {
+			sum++
+		} else if c, ok := children[row.ID]; ok && c.total > 0 {
+			sum += float64(c.done) / float64(c.total)
+		}
+	}
+	if topLevel > 0 {
+		progress = sum / float64(topLevel) * 100
+	}
+	return subtasks, subtasksCompleted, progress, nil
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TagService struct {
+	db    *gorm.DB
+	tasks *TaskService
+}
+
+func NewTagService(db *gorm.DB, tasks *TaskService) *TagService {
+	return &TagService{db: db, tasks: tasks}
+}
+
+// CreateTag creates a new tag for a user
+func (s *TagService) CreateTag(userID uint, req *models.CreateTagRequest) (*models.Tag, error) {
+	name := models.NormalizeTagName(req.Name)
+	if name == "" {
+		return nil, errors.New("tag name is required")
+	}
+	if err := s.checkNameAvailable(userID, name, 0); err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{Name: name, Color: models.DefaultTagColor, UserID: userID}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+	if err := s.db.Create(tag).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return tag, nil
+}
+
+// GetTags returns a user's tags ordered by name
+func (s *TagService) GetTags(userID uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := s.db.Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetTagByID retrieves a tag by ID for a specific user
+func (s *TagService) GetTagByID(userID, tagID uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := s.db.Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag not found")
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// UpdateTag renames or recolors a tag
+func (s *TagService) UpdateTag(userID, tagID uint, req *models.UpdateTagRequest) (*models.Tag, error) {
+	tag, err := s.GetTagByID(userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := models.NormalizeTagName(*req.Name)
+		if name == "" {
+			return nil, errors.New("tag name is required")
+		}
+		if name != tag.Name {
+			if err := s.checkNameAvailable(userID, name, tag.ID); err != nil {
+				return nil, err
+			}
+			tag.Name = name
+		}
+	}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(tag).Error; err != nil {
+			return err
+		}
+		// Tagged tasks, and the parents listing them as subtasks, show the tag
+		tagged := "SELECT task_id FROM task_tags WHERE tag_id = ?"
+		return tx.Model(&models.Task{}).
+			Where("id IN ("+tagged+") OR id IN (SELECT parent_id FROM tasks WHERE id IN ("+tagged+"))", tag.ID, tag.ID).
+			UpdateColumn("version", bumpVersion).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+	return tag, nil
+}
+
+// DeleteTag deletes a tag and detaches it from every task
+func (s *TagService) DeleteTag(userID, tagID uint) error {
+	tag, err := s.GetTagByID(userID, tagID)
+	if err != nil {
+		return err
+	}
+
+	err = s.tasks.transaction(func(tx *gorm.DB) error {
+		var tasks []models.Task
+		if err := tx.Where("id IN (SELECT task_id FROM task_tags WHERE tag_id = ?)", tag.ID).Find(&tasks).Error; err != nil {
+			return err
+		}
+		befores := make([]taskSnapshot, len(tasks))
+		for i := range tasks {
+			if err := loadTaskTags(tx, &tasks[i]); err != nil {
+				return err
+			}
+			befores[i] = taskSnapshot{}.withTags(tasks[i].Tags)
+		}
+		if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		taskIDs := make([]uint, len(tasks))
+		for i := range tasks {
+			taskIDs[i] = tasks[i].ID
+		}
+		if err := touchTasks(tx, taskIDs); err != nil {
+			return err
+		}
+		for i := range tasks {
+			if err := loadTaskTags(tx, &tasks[i]); err != nil {
+				return err
+			}
+			changes := diffSnapshots(befores[i], taskSnapshot{}.withTags(tasks[i].Tags))
+			if err := recordTaskEvent(tx, userID, tasks[i].ID, models.OperationUpdate, changes); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(tag).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+func (s *TagService) checkNameAvailable(userID uint, name string, exceptID uint) error {
+	var existing int64
+	err := s.db.Model(&models.Tag{}).
+		Where("user_id = ? AND LOWER(name) = ? AND id <> ?", userID, name, exceptID).
+		Count(&existing).Error
+	if err != nil {
+		return fmt.Errorf("failed to check tag name: %w", err)
+	}
+	if existing > 0 {
+		return errors.New("tag already exists")
+	}
+	return nil
+}
+
+// resolveTags returns the user's tags with the given names, creating any that do not exist yet
+func resolveTags(tx *gorm.DB, userID uint, names []string) ([]models.Tag, error) {
+	seen := make(map[string]bool, len(names))
+	var unique []string
+	for _, name := range names {
+		name = models.NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	if len(unique) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	// Tags named before names were lower-cased are matched regardless of case
+	var tags []models.Tag
+	if err := tx.Where("user_id = ? AND LOWER(name) IN ?", userID, unique).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		found[models.NormalizeTagName(tag.Name)] = true
+	}
+	for _, name := range unique {
+		if found[name] {
+			continue
+		}
+		tag := models.Tag{Name: name, Color: models.DefaultTagColor, UserID: userID}
+		if err := tx.Create(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// replaceTaskTags sets the task's tags to the named tags, resolving names against the
+// acting user's tags
+func replaceTaskTags(tx *gorm.DB, userID uint, task *models.Task, names []string) error {
+	tags, err := resolveTags(tx, userID, names)
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(task).Association("Tags").Replace(tags); err != nil {
+		return err
+	}
+	return loadTaskTags(tx, task)
+}
+
+// loadTaskTags loads the task's tags ordered by name
+func loadTaskTags(db *gorm.DB, task *models.Task) error {
+	task.Tags = nil
+	return db.Model(task).Order("tags.name ASC").Association("Tags").Find(&task.Tags)
+}
+
+// filterByTags restricts query to tasks carrying any (or, in TagModeAll, every) of the named
+// tags. Tags match by name regardless of case, so tasks tagged by teammates in shared
+// projects are included.
+func filterByTags(query *gorm.DB, names []string, mode string) *gorm.DB {
+	tagged := query.Session(&gorm.Session{NewDB: true}).
+		Table("task_tags").
+		Select("task_tags.task_id").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("LOWER(tags.name) IN ?", names)
+	if mode == models.TagModeAll {
+		tagged = tagged.Group("task_tags.task_id").Having("COUNT(DISTINCT LOWER(tags.name)) = ?", len(names))
+	}
+	return query.Where("tasks.id IN (?)", tagged)
+}
+
+// tagStats counts the visible tasks carrying each of the user's tags; tags without tasks
+// are included with zero counts
+func (s *TaskService) tagStats(userID uint) ([]models.TagStats, error) {
+	stats := []models.TagStats{}
+	err := s.db.Table("tags").
+		Select(`tags.id AS tag_id, tags.name, tags.color,
+			COUNT(tasks.id) AS total,
+			COALESCE(SUM(CASE WHEN tasks.status = ? THEN 1 ELSE 0 END), 0) AS pending,
+			COALESCE(SUM(CASE WHEN tasks.status = ? THEN 1 ELSE 0 END), 0) AS completed,
+			COALESCE(SUM(CASE WHEN tasks.status <> ? AND tasks.due_date < ? THEN 1 ELSE 0 END), 0) AS overdue`,
+			models.StatusPending, models.StatusCompleted, models.StatusCompleted, time.Now()).
+		Joins("LEFT JOIN task_tags ON task_tags.tag_id = tags.id").
+		Joins("LEFT JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL AND "+visibleTasksCondition, userID, userID).
+		Where("tags.user_id = ?", userID).
+		Group("tags.id, tags.name, tags.color").
+		Order("tags.name ASC").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// priorityWeightSQL computes Task.GetPriorityWeight in SQL so that cursors taken from loaded
+// tasks line up with the query order
+const priorityWeightSQL = "CASE WHEN tasks.priority = 'high' THEN 3 WHEN tasks.priority = 'low' THEN 1 ELSE 2 END"
+
+// taskSortColumn maps a sort field to the SQL it orders by and the value a task holds for it
+type taskSortColumn struct {
+	expr     string
+	nullable bool
+	value    func(task *models.Task) interface{}
+	// decode parses a cursor value back into the type value returns
+	decode func(raw json.RawMessage) (interface{}, error)
+}
+
+var taskSortColumns = map[string]taskSortColumn{
+	"priority":  {expr: priorityWeightSQL, value: func(t *models.Task) interface{} { return t.GetPriorityWeight() }, decode: decodeInt},
+	"dueDate":   {expr: "tasks.due_date", nullable: true, value: func(t *models.Task) interface{} { return t.DueDate }, decode: decodeTime},
+	"createdAt": {expr: "tasks.created_at", value: func(t *models.Task) interface{} { return t.CreatedAt }, decode: decodeTime},
+	"updatedAt": {expr: "tasks.updated_at", value: func(t *models.Task) interface{} { return t.UpdatedAt }, decode: decodeTime},
+	"title":     {expr: "tasks.title", value: func(t *models.Task) interface{} { return t.Title }, decode: decodeString},
+	"position":  {expr: "tasks.position", value: func(t *models.Task) interface{} { return t.Position }, decode: decodeInt},
+}
+
+// taskSortKey is one key of a resolved sort order
+type taskSortKey struct {
+	taskSortColumn
+	descending bool
+}
+
+// taskSortKeys resolves sort fields to columns and appends the task ID as a tiebreaker, so
+// that the order is total and cursors never skip or repeat tasks
+func taskSortKeys(fields []models.SortField) []taskSortKey {
+	keys := make([]taskSortKey, 0, len(fields)+1)
+	for _, field := range fields {
+		keys = append(keys, taskSortKey{taskSortColumns[field.Name], field.Descending})
+	}
+	id := taskSortColumn{
+		expr:   "tasks.id",
+		value:  func(t *models.Task) interface{} { return t.ID },
+		decode: decodeInt,
+	}
+	return append(keys, taskSortKey{id, keys[len(keys)-1].descending})
+}
+
+// taskListOrder builds the ORDER BY clause for keys. Nulls sort last, and a backward page
+// walks the same order in reverse.
+func taskListOrder(keys []taskSortKey, backward bool) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ascending := key.descending == backward
+		if key.nullable {
+			parts = append(parts, key.expr+" IS NULL "+direction(!backward))
+		}
+		parts = append(parts, key.expr+" "+direction(ascending))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func direction(ascending bool) string {
+	if ascending {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// taskCursor is the position of a task in a listing order. A backward cursor selects the
+// tasks before it instead of those after it.
+type taskCursor struct {
+	// Sort is the sort the cursor was taken under; it must match the request's
+	Sort     string            `json:"s"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+	values   []interface{}
+}
+
+func newTaskCursor(sort string, keys []taskSortKey, task *models.Task, backward bool) *taskCursor {
+	cursor := &taskCursor{Sort: sort, Backward: backward}
+	for _, key := range keys {
+		value := key.value(task)
+		if t, ok := value.(*time.Time); ok && t == nil {
+			value = nil
+		}
+		raw, _ := json.Marshal(value)
+		cursor.Values = append(cursor.Values, raw)
+	}
+	return cursor
+}
+
+func (c *taskCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeTaskCursor parses a cursor taken under sort
+func decodeTaskCursor(cursor, sort string, keys []taskSortKey) (*taskCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var c taskCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sort || len(c.Values) != len(keys) {
+		return nil, errors.New("invalid cursor")
+	}
+	for i, key := range keys {
+		if string(c.Values[i]) == "null" {
+			if !key.nullable {
+				return nil, errors.New("invalid cursor")
+			}
+			c.values = append(c.values, nil)
+			continue
+		}
+		value, err := key.decode(c.Values[i])
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		c.values = append(c.values, value)
+	}
+	return &c, nil
+}
+
+// apply restricts query to the tasks strictly after (or, for a backward cursor, before)
+// the cursor position: those that tie on the first keys and come later on the next one
+func (c *taskCursor) apply(query *gorm.DB, keys []taskSortKey) *gorm.DB {
+	var clauses, ties []string
+	var args, tieArgs []interface{}
+	for i, key := range keys {
+		value := c.values[i]
+		if clause, clauseArgs := c.beyond(key, value); clause != "" {
+			clauses = append(clauses, "("+strings.Join(append(append([]string{}, ties...), clause), " AND ")+")")
+			args = append(append(args, tieArgs...), clauseArgs...)
+		}
+		if value == nil {
+			ties = append(ties, key.expr+" IS NULL")
+		} else {
+			ties = append(ties, key.expr+" = ?")
+			tieArgs = append(tieArgs, value)
+		}
+	}
+	if len(clauses) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where(strings.Join(clauses, " OR "), args...)
+}
+
+// beyond returns the condition for coming strictly later than value on key, in the
+// cursor's direction. Nulls sort last, so nothing comes after a null going forward.
+func (c *taskCursor) beyond(key taskSortKey, value interface{}) (string, []interface{}) {
+	op := ">"
+	if key.descending != c.Backward {
+		op = "<"
+	}
+	switch {
+	case !key.nullable:
+		return key.expr + " " + op + " ?", []interface{}{value}
+	case c.Backward && value == nil:
+		return key.expr + " IS NOT NULL", nil
+	case c.Backward:
+		return "(" + key.expr + " IS NOT NULL AND " + key.expr + " " + op + " ?)", []interface{}{value}
+	case value == nil:
+		return "", nil
+	default:
+		return "(" + key.expr + " IS NULL OR " + key.expr + " " + op + " ?)", []interface{}{value}
+	}
+}
+
+func decodeInt(raw json.RawMessage) (interface{}, error) {
+	var value int64
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+func decodeTime(raw json.RawMessage) (interface{}, error) {
+	var value time.Time
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+func decodeString(raw json.RawMessage) (interface{}, error) {
+	var value string
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
@@ -3,15 +3,21 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"task-manager-backend/internal/filter"
 	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/search"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TaskService struct {
-	db *gorm.DB
+	db        *gorm.DB
+	publisher TaskPublisher
+	relay     *OutboxRelay
 }
 
 func NewTaskService(db *gorm.DB) *TaskService {
@@ -20,6 +26,39 @@ func NewTaskService(db *gorm.DB) *TaskService {
 
 // CreateTask creates a new task
 func (s *TaskService) CreateTask(userID uint, req *models.CreateTaskRequest) (*models.Task, error) {
+	task, err := newTaskFromRequest(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.ProjectID != nil && *req.ProjectID != 0 {
+		if err := requireProjectEditor(s.db, userID, *req.ProjectID); err != nil {
+			return nil, err
+		}
+		task.ProjectID = req.ProjectID
+	}
+
+	err = s.transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		after := snapshotTask(task)
+		if len(req.Tags) > 0 {
+			if err := replaceTaskTags(tx, userID, task, req.Tags); err != nil {
+				return err
+			}
+			after.withTags(task.Tags)
+		}
+		return recordTaskEvent(tx, userID, task.ID, models.OperationCreate, diffSnapshots(nil, after))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	return task, nil
+}
+
+// newTaskFromRequest builds an unsaved task from a create request
+func newTaskFromRequest(userID uint, req *models.CreateTaskRequest) (*models.Task, error) {
 	task := &models.Task{
 		Title:       req.Title,
 		Description: req.Description,
@@ -32,66 +71,264 @@ func (s *TaskService) CreateTask(userID uint, req *models.CreateTaskRequest) (*m
 	} else {
 		task.Priority = models.PriorityMedium
 	}
-
-	if err := s.db.Create(task).Error; err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
+	if req.AutoComplete != nil {
+		task.AutoComplete = *req.AutoComplete
+	}
+	if req.Position != nil {
+		task.Position = *req.Position
+	}
+	if req.Recurrence != nil {
+		if err := applyRecurrence(task, *req.Recurrence); err != nil {
+			return nil, err
+		}
 	}
 
 	return task, nil
 }
 
-// GetTaskByID retrieves a task by ID for a specific user
+// GetTaskByID retrieves a task by ID if it is visible to the user
 func (s *TaskService) GetTaskByID(userID, taskID uint) (*models.Task, error) {
 	var task models.Task
-	err := s.db.Where("id = ? AND user_id = ?", taskID, userID).First(&task).Error
+	err := s.db.Scopes(visibleTo(userID)).Where("tasks.id = ?", taskID).First(&task).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("task not found")
 		}
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
-	return &task, nil
+	tasks := []models.Task{task}
+	if err := markBlocked(s.db, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
 }
 
-// GetTasksByUser retrieves tasks for a user with filtering and pagination
+// GetTasksByUser retrieves a page of the tasks visible to a user along with their total count
 func (s *TaskService) GetTasksByUser(userID uint, filter *models.TaskFilter) ([]models.Task, int64, error) {
-	query := s.db.Where("user_id = ?", userID)
+	counted := *filter
+	count := true
+	counted.Count = &count
+	page, err := s.ListTasks(userID, &counted)
+	if err != nil {
+		return nil, 0, err
+	}
+	return page.Tasks, *page.Total, nil
+}
+
+// ListTasks retrieves the tasks visible to a user with filtering, sorting and pagination. A
+// cursor from an earlier page selects keyset paging; otherwise Page is used as an offset.
+func (s *TaskService) ListTasks(userID uint, filter *models.TaskFilter) (*models.TaskPage, error) {
+	fields, err := filter.SortFields()
+	if err != nil {
+		return nil, err
+	}
+	sort := sortString(fields)
+	keys := taskSortKeys(fields)
+	// Search results are ordered by relevance unless a sort is given; cursors cannot
+	// capture relevance
+	ranked := filter.Q != "" && filter.Sort == ""
+
+	var cursor *taskCursor
+	if filter.Cursor != "" {
+		if ranked {
+			return nil, errors.New("cursor cannot be combined with search")
+		}
+		if cursor, err = decodeTaskCursor(filter.Cursor, sort, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	query := s.db.Scopes(visibleTo(userID))
 
 	// Apply filters
+	if filter.ProjectID != nil {
+		if *filter.ProjectID == 0 {
+			query = query.Where("tasks.project_id IS NULL")
+		} else {
+			query = query.Where("tasks.project_id = ?", *filter.ProjectID)
+		}
+	}
 	if filter.Status != nil {
 		query = query.Where("status = ?", *filter.Status)
 	}
 	if filter.Priority != nil {
 		query = query.Where("priority = ?", *filter.Priority)
 	}
+	if filter.Assignee != "" {
+		assigneeID, err := filter.AssigneeUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		query = filterByAssignee(query, assigneeID)
+	}
 	if filter.Overdue != nil && *filter.Overdue {
 		query = query.Where("due_date < ? AND status != ?", time.Now(), models.StatusCompleted)
 	}
+	if filter.View == models.TaskViewNested {
+		query = query.Where("parent_id IS NULL").
+			Preload("Subtasks", func(db *gorm.DB) *gorm.DB {
+				return db.Order("created_at ASC, id ASC")
+			}).
+			Preload("Subtasks.Tags", orderTagsByName)
+	}
+	if filter.Q != "" {
+		query = search.Filter(query, filter.Q)
+	}
+	if names := filter.TagNames(); len(names) > 0 {
+		query = filterByTags(query, names, filter.TagMode)
+	}
+	expr, err := filter.Expression()
+	if err != nil {
+		return nil, err
+	}
+	if expr != nil {
+		query = query.Where(compileFilter(expr, userID))
+	}
 
-	// Count total records
-	var total int64
-	if err := query.Model(&models.Task{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	page := &models.TaskPage{}
+	if filter.CountTotal() {
+		var total int64
+		if err := query.Model(&models.Task{}).Count(&total).Error; err != nil {
+			return nil, fmt.Errorf("failed to count tasks: %w", err)
+		}
+		page.Total = &total
 	}
 
-	// Apply pagination
-	offset := (filter.Page - 1) * filter.Limit
-	query = query.Offset(offset).Limit(filter.Limit)
+	// Apply pagination, fetching one extra task to learn whether another page follows
+	if cursor != nil {
+		query = cursor.apply(query, keys)
+	} else {
+		query = query.Offset((filter.Page - 1) * filter.Limit)
+	}
+	query = query.Limit(filter.Limit + 1)
 
-	// Order by priority (high to low) and creation date (newest first)
-	query = query.Order("CASE WHEN priority = 'high' THEN 3 WHEN priority = 'medium' THEN 2 ELSE 1 END DESC, created_at DESC")
+	if ranked {
+		query = search.Rank(query, filter.Q)
+	}
+	backward := cursor != nil && cursor.Backward
+	query = query.Order(taskListOrder(keys, backward))
+
+	// Skip loading tags when the response leaves them out
+	if names, _ := filter.FieldNames(); names == nil || containsName(names, "tags") {
+		query = query.Preload("Tags", orderTagsByName)
+	}
 
 	var tasks []models.Task
 	if err := query.Find(&tasks).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to get tasks: %w", err)
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	more := len(tasks) > filter.Limit
+	if more {
+		tasks = tasks[:filter.Limit]
+	}
+	if backward {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+	// Snippets come with the rank, so sorted results go without them on every dialect
+	if ranked {
+		if search.IsPostgres(s.db) {
+			formatHeadlines(tasks)
+		} else {
+			highlightTasks(tasks, search.Terms(filter.Q))
+		}
+	}
+	if err := markBlocked(s.db, tasks); err != nil {
+		return nil, err
 	}
+	for i := range tasks {
+		if err := markBlocked(s.db, tasks[i].Subtasks); err != nil {
+			return nil, err
+		}
+	}
+
+	page.Tasks = tasks
+	if len(tasks) > 0 && !ranked {
+		first, last := &tasks[0], &tasks[len(tasks)-1]
+		switch {
+		case backward:
+			page.NextCursor = newTaskCursor(sort, keys, last, false).encode()
+			if more {
+				page.PrevCursor = newTaskCursor(sort, keys, first, true).encode()
+			}
+		default:
+			if more {
+				page.NextCursor = newTaskCursor(sort, keys, last, false).encode()
+			}
+			if cursor != nil || filter.Page > 1 {
+				page.PrevCursor = newTaskCursor(sort, keys, first, true).encode()
+			}
+		}
+	}
+	return page, nil
+}
+
+// compileFilter resolves a filter expression for the user at the current time
+func compileFilter(expr filter.Expr, userID uint) clause.Expr {
+	return filter.Compile(expr, filter.Context{Now: time.Now(), UserID: userID})
+}
 
-	return tasks, total, nil
+// sortString formats sort fields the way the sort query parameter spells them
+func sortString(fields []models.SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Descending {
+			parts = append(parts, "-"+field.Name)
+		} else {
+			parts = append(parts, field.Name)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func orderTagsByName(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name ASC")
+}
+
+// formatHeadlines turns the ts_headline snippets into escaped HTML
+func formatHeadlines(tasks []models.Task) {
+	for i := range tasks {
+		if tasks[i].Snippet != nil {
+			snippet := search.FormatHeadline(*tasks[i].Snippet)
+			tasks[i].Snippet = &snippet
+		}
+	}
+}
+
+// highlightTasks fills in search snippets on dialects without ts_headline
+func highlightTasks(tasks []models.Task, terms []string) {
+	for i := range tasks {
+		text := tasks[i].Title
+		if tasks[i].Description != nil {
+			text += " " + *tasks[i].Description
+		}
+		snippet := search.Highlight(text, terms)
+		tasks[i].Snippet = &snippet
+	}
 }
 
 // UpdateTask updates an existing task
 func (s *TaskService) UpdateTask(userID, taskID uint, req *models.UpdateTaskRequest) (*models.Task, error) {
-	task, err := s.GetTaskByID(userID, taskID)
+	task, err := s.getEditableTask(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireVersion(task, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	wasCompleted := task.IsCompleted()
+	before, err := s.snapshotForUpdate(task, req.Tags != nil)
 	if err != nil {
 		return nil, err
 	}
@@ -112,64 +349,248 @@ func (s *TaskService) UpdateTask(userID, taskID uint, req *models.UpdateTaskRequ
 	if req.DueDate != nil {
 		task.DueDate = req.DueDate
 	}
-
-	if err := s.db.Save(task).Error; err != nil {
-		return nil, fmt.Errorf("failed to update task: %w", err)
+	if req.AutoComplete != nil {
+		task.AutoComplete = *req.AutoComplete
+	}
+	if req.Position != nil {
+		task.Position = *req.Position
+	}
+	if req.Recurrence != nil {
+		if err := applyRecurrence(task, *req.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+	moved := false
+	if req.ProjectID != nil {
+		if moved, err = s.moveToProject(userID, task, *req.ProjectID); err != nil {
+			return nil, err
+		}
 	}
 
+	if err := s.saveTaskUpdate(userID, task, before, wasCompleted, moved, req.Tags); err != nil {
+		return nil, err
+	}
 	return task, nil
 }
 
-// DeleteTask deletes a task (soft delete)
+// snapshotForUpdate captures a task before it is changed, including its tags when the
+// update is going to replace them
+func (s *TaskService) snapshotForUpdate(task *models.Task, withTags bool) (taskSnapshot, error) {
+	before := snapshotTask(task)
+	if withTags {
+		var current []models.Tag
+		if err := s.db.Model(task).Association("Tags").Find(&current); err != nil {
+			return nil, fmt.Errorf("failed to get tags: %w", err)
+		}
+		before.withTags(current)
+	}
+	return before, nil
+}
+
+// saveTaskUpdate persists the changes applied to a loaded task: it writes the task with a
+// version check, keeps subtasks in a moved task's project, replaces the tags when given,
+// records the event and completes or spawns related tasks. A blocked task cannot be completed.
+func (s *TaskService) saveTaskUpdate(userID uint, task *models.Task, before taskSnapshot, wasCompleted, moved bool, tags *[]string) error {
+	if !wasCompleted && task.IsCompleted() {
+		if err := requireUnblocked(s.db, task); err != nil {
+			return err
+		}
+	}
+	err := s.transaction(func(tx *gorm.DB) error {
+		if err := saveTask(tx, task); err != nil {
+			return err
+		}
+		if moved {
+			var subtasks []models.Task
+			if err := tx.Where("parent_id = ?", task.ID).Find(&subtasks).Error; err != nil {
+				return err
+			}
+			// Subtasks always live in their parent's project
+			err := tx.Model(&models.Task{}).Where("parent_id = ?", task.ID).
+				Updates(map[string]interface{}{"project_id": task.ProjectID, "version": bumpVersion}).Error
+			if err != nil {
+				return err
+			}
+			if err := unassignInaccessible(tx, task); err != nil {
+				return err
+			}
+			// The task's own event below covers it; its subtasks get one each
+			if err := recordBulkUpdate(tx, userID, subtasks, models.OperationUpdate); err != nil {
+				return err
+			}
+		}
+		after := snapshotTask(task)
+		if tags != nil {
+			if err := replaceTaskTags(tx, userID, task, *tags); err != nil {
+				return err
+			}
+			after.withTags(task.Tags)
+		}
+		if changes := diffSnapshots(before, after); len(changes) > 0 {
+			if err := recordTaskEvent(tx, userID, task.ID, models.OperationUpdate, changes); err != nil {
+				return err
+			}
+		}
+		if !wasCompleted && task.IsCompleted() {
+			if err := spawnNextOccurrence(tx, userID, task); err != nil {
+				return err
+			}
+		}
+		if err := syncTaskHierarchy(tx, userID, task); err != nil {
+			return err
+		}
+		if tags != nil {
+			return nil
+		}
+		return loadTaskTags(tx, task)
+	})
+	if errors.Is(err, errVersionMismatch) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	return nil
+}
+
+// DeleteTask moves a task, its subtasks and their comments to the trash (soft delete). They
+// share one deletion time so that RestoreTask brings back exactly what was deleted together.
 func (s *TaskService) DeleteTask(userID, taskID uint) error {
-	result := s.db.Where("id = ? AND user_id = ?", taskID, userID).Delete(&models.Task{})
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete task: %w", result.Error)
+	task, err := s.getEditableTask(userID, taskID)
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
-		return errors.New("task not found")
+
+	deletedAt := time.Now().UTC().Truncate(time.Microsecond)
+	err = s.transaction(func(tx *gorm.DB) error {
+		tx = tx.Session(&gorm.Session{NowFunc: func() time.Time { return deletedAt }})
+		var subtasks []models.Task
+		if err := tx.Where("parent_id = ?", task.ID).Find(&subtasks).Error; err != nil {
+			return err
+		}
+		for i := range subtasks {
+			if err := recordTaskEvent(tx, userID, subtasks[i].ID, models.OperationDelete, diffSnapshots(snapshotTask(&subtasks[i]), nil)); err != nil {
+				return err
+			}
+		}
+		if err := recordTaskEvent(tx, userID, task.ID, models.OperationDelete, diffSnapshots(snapshotTask(task), nil)); err != nil {
+			return err
+		}
+		if err := deleteTaskComments(tx, "id = ? OR parent_id = ?", task.ID, task.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("parent_id = ?", task.ID).Delete(&models.Task{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(task).Error; err != nil {
+			return err
+		}
+		if task.ParentID != nil {
+			return syncParentStatus(tx, userID, *task.ParentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
 	}
 	return nil
 }
 
-// GetTaskStats returns task statistics for a user
+// GetTaskStats returns statistics over the tasks visible to a user
 func (s *TaskService) GetTaskStats(userID uint) (*models.TaskStats, error) {
 	stats := &models.TaskStats{}
+	tasks := func() *gorm.DB {
+		return s.db.Model(&models.Task{}).Scopes(visibleTo(userID))
+	}
 
 	// Total tasks
-	if err := s.db.Model(&models.Task{}).Where("user_id = ?", userID).Count(&stats.Total).Error; err != nil {
+	if err := tasks().Count(&stats.Total).Error; err != nil {
 		return nil, fmt.Errorf("failed to count total tasks: %w", err)
 	}
 
 	// Pending tasks
-	if err := s.db.Model(&models.Task{}).Where("user_id = ? AND status = ?", userID, models.StatusPending).Count(&stats.Pending).Error; err != nil {
+	if err := tasks().Where("status = ?", models.StatusPending).Count(&stats.Pending).Error; err != nil {
 		return nil, fmt.Errorf("failed to count pending tasks: %w", err)
 	}
 
 	// Completed tasks
-	if err := s.db.Model(&models.Task{}).Where("user_id = ? AND status = ?", userID, models.StatusCompleted).Count(&stats.Completed).Error; err != nil {
+	if err := tasks().Where("status = ?", models.StatusCompleted).Count(&stats.Completed).Error; err != nil {
 		return nil, fmt.Errorf("failed to count completed tasks: %w", err)
 	}
 
 	// Overdue tasks
-	if err := s.db.Model(&models.Task{}).
-		Where("user_id = ? AND due_date < ? AND status != ?", userID, time.Now(), models.StatusCompleted).
+	if err := tasks().
+		Where("due_date < ? AND status != ?", time.Now(), models.StatusCompleted).
 		Count(&stats.Overdue).Error; err != nil {
 		return nil, fmt.Errorf("failed to count overdue tasks: %w", err)
 	}
 
+	// Tasks the user created and tasks assigned to the user
+	createdByMe, err := countTasks(tasks().Where("tasks.user_id = ?", userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count created tasks: %w", err)
+	}
+	stats.CreatedByMe = createdByMe
+
+	assignedToMe, err := countTasks(tasks().Where("tasks.assignee_id = ?", userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count assigned tasks: %w", err)
+	}
+	stats.AssignedToMe = assignedToMe
+
+	// Subtask progress
+	subtasks, subtasksCompleted, progress, err := s.subtaskProgress(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute subtask progress: %w", err)
+	}
+	stats.Subtasks = subtasks
+	stats.SubtasksCompleted = subtasksCompleted
+	stats.Progress = progress
+
+	// Per-tag breakdown
+	byTag, err := s.tagStats(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks per tag: %w", err)
+	}
+	stats.ByTag = byTag
+
 	return stats, nil
 }
 
-// MarkTaskAsCompleted marks a task as completed
+// MarkTaskAsCompleted marks a task as completed, refusing while one of its blockers is pending
 func (s *TaskService) MarkTaskAsCompleted(userID, taskID uint) (*models.Task, error) {
-	task, err := s.GetTaskByID(userID, taskID)
+	return s.CompleteTask(userID, taskID, false)
+}
+
+// CompleteTask marks a task as completed. With force a task is completed even when one of
+// its blockers is still pending; it keeps IsBlocked set to tell the caller.
+func (s *TaskService) CompleteTask(userID, taskID uint, force bool) (*models.Task, error) {
+	task, err := s.getEditableTask(userID, taskID)
 	if err != nil {
 		return nil, err
 	}
+	if task.IsBlocked && !force {
+		return nil, errors.New("task is blocked")
+	}
 
+	before := snapshotTask(task)
 	task.MarkAsCompleted()
-	if err := s.db.Save(task).Error; err != nil {
+	err = s.transaction(func(tx *gorm.DB) error {
+		if err := saveTask(tx, task); err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, userID, task.ID, models.OperationComplete, diffSnapshots(before, snapshotTask(task))); err != nil {
+			return err
+		}
+		if err := spawnNextOccurrence(tx, userID, task); err != nil {
+			return err
+		}
+		return syncTaskHierarchy(tx, userID, task)
+	})
+	if errors.Is(err, errVersionMismatch) {
+		return nil, err
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to mark task as completed: %w", err)
 	}
 
@@ -178,13 +599,26 @@ func (s *TaskService) MarkTaskAsCompleted(userID, taskID uint) (*models.Task, er
 
 // MarkTaskAsPending marks a task as pending
 func (s *TaskService) MarkTaskAsPending(userID, taskID uint) (*models.Task, error) {
-	task, err := s.GetTaskByID(userID, taskID)
+	task, err := s.getEditableTask(userID, taskID)
 	if err != nil {
 		return nil, err
 	}
 
+	before := snapshotTask(task)
 	task.MarkAsPending()
-	if err := s.db.Save(task).Error; err != nil {
+	err = s.transaction(func(tx *gorm.DB) error {
+		if err := saveTask(tx, task); err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, userID, task.ID, models.OperationReopen, diffSnapshots(before, snapshotTask(task))); err != nil {
+			return err
+		}
+		return syncTaskHierarchy(tx, userID, task)
+	})
+	if errors.Is(err, errVersionMismatch) {
+		return nil, err
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to mark task as pending: %w", err)
 	}
 
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"task-manager-backend/internal/middleware"
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TokenService struct {
+	db         *gorm.DB
+	issuer     middleware.TokenIssuer
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenService(db *gorm.DB, issuer middleware.TokenIssuer, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		db:         db,
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// IssueTokens starts a new session for the user and returns an access/refresh token pair
+func (s *TokenService) IssueTokens(user *models.User) (*models.AuthResponse, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	rawRefresh, refresh, err := s.newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(refresh).Error; err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	resp, err := s.buildResponse(user.ID, rawRefresh, refresh)
+	if err != nil {
+		return nil, err
+	}
+	resp.User = user
+	return resp, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token is
+// consumed; presenting an already consumed token revokes the whole session.
+func (s *TokenService) Refresh(rawToken string) (*models.AuthResponse, error) {
+	var current models.RefreshToken
+	err := s.db.Where("token_hash = ?", hashToken(rawToken)).First(&current).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if current.IsRevoked() {
+		return nil, errors.New("invalid refresh token")
+	}
+	if current.IsUsed() {
+		if err := s.revokeFamily(current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reused")
+	}
+	if current.IsExpired() {
+		return nil, errors.New("refresh token expired")
+	}
+
+	rawRefresh, next, err := s.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	reused := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Consume the token only if nobody else has; this keeps rotation single-use under concurrency
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return nil
+		}
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if reused {
+		if err := s.revokeFamily(current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reused")
+	}
+
+	return s.buildResponse(current.UserID, rawRefresh, next)
+}
+
+// Logout revokes the session the refresh token belongs to
+func (s *TokenService) Logout(rawToken string) error {
+	var current models.RefreshToken
+	err := s.db.Where("token_hash = ?", hashToken(rawToken)).First(&current).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invalid refresh token")
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return s.revokeFamily(current.FamilyID)
+}
+
+// IsSessionActive reports whether any token of the session is still unrevoked
+func (s *TokenService) IsSessionActive(sessionID string) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", sessionID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (s *TokenService) revokeFamily(familyID string) error {
+	err := s.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+func (s *TokenService) newRefreshToken(userID uint, familyID string) (string, *models.RefreshToken, error) {
+	raw, err := randomToken(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return raw, &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, nil
+}
+
+func (s *TokenService) buildResponse(userID uint, rawRefresh string, refresh *models.RefreshToken) (*models.AuthResponse, error) {
+	token, expiresAt, err := s.issuer.Issue(userID, refresh.FamilyID, s.accessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &models.AuthResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     rawRefresh,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+// randomToken returns n random bytes encoded as URL-safe base64
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 digest under which a refresh token is stored
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// trashedRootCondition leaves out subtasks whose parent is in the trash as well; they are
+// listed and restored through their parent
+const trashedRootCondition = "tasks.deleted_at IS NOT NULL AND (tasks.parent_id IS NULL OR " +
+	"NOT EXISTS (SELECT 1 FROM tasks parents WHERE parents.id = tasks.parent_id AND parents.deleted_at IS NOT NULL))"
+
+// GetTrash returns the deleted tasks visible to a user, most recently deleted first
+func (s *TaskService) GetTrash(userID uint, filter *models.TrashFilter) ([]models.TrashedTask, int64, error) {
+	query := s.db.Unscoped().Model(&models.Task{}).Scopes(visibleTo(userID)).Where(trashedRootCondition)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count trash: %w", err)
+	}
+
+	var tasks []models.Task
+	err := query.Preload("Tags", orderTagsByName).
+		Order("tasks.deleted_at DESC, tasks.id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	trashed := make([]models.TrashedTask, 0, len(tasks))
+	for _, task := range tasks {
+		trashed = append(trashed, models.TrashedTask{Task: task, DeletedAt: task.DeletedAt.Time})
+	}
+	return trashed, total, nil
+}
+
+// RestoreTask takes a task out of the trash together with the subtasks and comments that
+// were deleted with it. A subtask can only be restored while its parent is not deleted.
+func (s *TaskService) RestoreTask(userID, taskID uint) (*models.Task, error) {
+	task, err := s.getTrashableTask(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.DeletedAt.Valid {
+		return nil, errors.New("task is not deleted")
+	}
+	if task.ParentID != nil {
+		var parents int64
+		if err := s.db.Model(&models.Task{}).Where("id = ?", *task.ParentID).Count(&parents).Error; err != nil {
+			return nil, fmt.Errorf("failed to get parent task: %w", err)
+		}
+		if parents == 0 {
+			return nil, errors.New("parent task is deleted")
+		}
+	}
+
+	deletedAt := task.DeletedAt.Time
+	err = s.transaction(func(tx *gorm.DB) error {
+		var subtasks []models.Task
+		if err := tx.Unscoped().Where("parent_id = ? AND deleted_at = ?", task.ID, deletedAt).Find(&subtasks).Error; err != nil {
+			return err
+		}
+		restored := append(subtasks, *task)
+		ids := make([]uint, 0, len(restored))
+		for i := range restored {
+			ids = append(ids, restored[i].ID)
+		}
+
+		err := tx.Unscoped().Model(&models.Task{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": bumpVersion}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&models.Comment{}).Where("task_id IN ? AND deleted_at = ?", ids, deletedAt).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		for i := range restored {
+			if err := recordTaskEvent(tx, userID, restored[i].ID, models.OperationRestore, models.FieldChanges{}); err != nil {
+				return err
+			}
+		}
+		if task.ParentID != nil {
+			return syncParentStatus(tx, userID, *task.ParentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	return s.GetTaskByID(userID, task.ID)
+}
+
+// PurgeTask permanently deletes a task and its subtasks, whether or not they are in the trash.
+// Tasks that were not in the trash get a delete event, like DeleteTask would record.
+func (s *TaskService) PurgeTask(userID, taskID uint) error {
+	task, err := s.getTrashableTask(userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	err = s.transaction(func(tx *gorm.DB) error {
+		var tasks []models.Task
+		if err := tx.Unscoped().Where("id = ? OR parent_id = ?", task.ID, task.ID).Order("id ASC").Find(&tasks).Error; err != nil {
+			return err
+		}
+		taskIDs := make([]uint, 0, len(tasks))
+		for i := range tasks {
+			taskIDs = append(taskIDs, tasks[i].ID)
+			if tasks[i].DeletedAt.Valid {
+				continue
+			}
+			notePurgedTask(tx, &tasks[i])
+			if err := recordTaskEvent(tx, userID, tasks[i].ID, models.OperationDelete, diffSnapshots(snapshotTask(&tasks[i]), nil)); err != nil {
+				return err
+			}
+		}
+		if err := purgeTasks(tx, taskIDs); err != nil {
+			return err
+		}
+		if task.ParentID != nil && !task.DeletedAt.Valid {
+			return syncParentStatus(tx, userID, *task.ParentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to purge task: %w", err)
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes the tasks that were moved to the trash before the cutoff,
+// along with their subtasks. It returns the number of tasks deleted.
+func (s *TaskService) PurgeTrash(before time.Time) (int64, error) {
+	var purged int64
+	err := s.transaction(func(tx *gorm.DB) error {
+		expired := tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&models.Task{}).Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		var taskIDs []uint
+		err := tx.Unscoped().Model(&models.Task{}).Where("id IN (?) OR parent_id IN (?)", expired, expired).Pluck("id", &taskIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(taskIDs) == 0 {
+			return nil
+		}
+		purged = int64(len(taskIDs))
+		return purgeTasks(tx, taskIDs)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return purged, nil
+}
+
+// purgeTasks hard-deletes tasks and the rows that refer to them. Task events are immutable
+// and stay behind as the audit trail, and notifications keep their text without the link.
+// Outbox events and webhook deliveries carry a copy of the task rather than a reference,
+// so pending ones are still sent.
+func purgeTasks(tx *gorm.DB, taskIDs []uint) error {
+	if err := tx.Unscoped().Where("task_id IN ?", taskIDs).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id IN ? OR blocked_by_id IN ?", taskIDs, taskIDs).Delete(&models.TaskDependency{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id IN ?", taskIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.TaskReminder{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Notification{}).Where("task_id IN ?", taskIDs).Update("task_id", nil).Error; err != nil {
+		return err
+	}
+	err := tx.Unscoped().Model(&models.Task{}).Where("next_occurrence_id IN ?", taskIDs).
+		UpdateColumn("next_occurrence_id", nil).Error
+	if err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", taskIDs).Delete(&models.Task{}).Error
+}
+
+// getTrashableTask retrieves a task the user may change, including tasks in the trash
+func (s *TaskService) getTrashableTask(userID, taskID uint) (*models.Task, error) {
+	var task models.Task
+	err := s.db.Unscoped().Scopes(visibleTo(userID)).Where("tasks.id = ?", taskID).First(&task).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("task not found")
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if err := s.requireTaskEditor(userID, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
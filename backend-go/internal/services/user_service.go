@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"task-manager-backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// dummyPasswordHash is a bcrypt hash at the default cost that no password matches. Login
+// checks against it for unknown emails, so that they take as long as a wrong password.
+const dummyPasswordHash = "$2a$10$WVXvxwfAb6ymdmVG/D1RxOU8NjslynhM8tReCY2UBBam1KyD0XVmK"
+
+type UserService struct {
+	db *gorm.DB
+}
+
+func NewUserService(db *gorm.DB) *UserService {
+	return &UserService{db: db}
+}
+
+// Register creates a new user account
+func (s *UserService) Register(req *models.RegisterRequest) (*models.User, error) {
+	email := models.NormalizeEmail(req.Email)
+
+	var existing int64
+	if err := s.db.Model(&models.User{}).Where("email = ?", email).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if existing > 0 {
+		return nil, errors.New("email already registered")
+	}
+
+	user := &models.User{
+		Email: email,
+		Name:  req.Name,
+	}
+	if err := user.SetPassword(req.Password); err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.db.Create(user).Error; err != nil {
+		// A concurrent signup with the same email got in after the check above
+		if isUniqueViolation(err) {
+			return nil, errors.New("email already registered")
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// Login verifies the credentials and returns the matching user. Unknown emails are checked
+// against a dummy hash so the response time does not tell which emails are registered.
+func (s *UserService) Login(req *models.LoginRequest) (*models.User, error) {
+	var user models.User
+	err := s.db.Where("email = ?", models.NormalizeEmail(req.Email)).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			(&models.User{PasswordHash: dummyPasswordHash}).CheckPassword(req.Password)
+			return nil, errors.New("invalid credentials")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !user.CheckPassword(req.Password) {
+		return nil, errors.New("invalid credentials")
+	}
+
+	return &user, nil
+}
+
+// GetUserByID retrieves a user by ID
+func (s *UserService) GetUserByID(userID uint) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// isUniqueViolation reports whether err comes from a unique constraint, as raised by
+// PostgreSQL or SQLite
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
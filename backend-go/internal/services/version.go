@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errVersionMismatch is returned when a task changed between being read and written
+var errVersionMismatch = errors.New("version mismatch")
+
+// bumpVersion is the column expression that advances a task's version in bulk updates
+var bumpVersion = gorm.Expr("version + 1")
+
+// touchTasks advances the versions of tasks without changing anything else, for changes
+// that only show in their representation, such as their blockers or the names of their tags
+func touchTasks(tx *gorm.DB, taskIDs []uint) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.Task{}).Where("id IN ?", taskIDs).UpdateColumn("version", bumpVersion).Error
+}
+
+// touchRelatedTasks advances the versions of the tasks whose representation includes the
+// tasks changed in a transaction: the parents listing them as subtasks and, when their status
+// changed or they were deleted or restored, the tasks they block. The changed tasks have
+// advanced their own versions already.
+func touchRelatedTasks(tx *gorm.DB, recorded *recordedEvents) error {
+	changed := map[uint]bool{}
+	var taskIDs, blockerIDs, related []uint
+	for _, event := range recorded.events {
+		changed[event.TaskID] = true
+		taskIDs = append(taskIDs, event.TaskID)
+		if _, ok := event.Changes["status"]; ok || event.Operation == models.OperationDelete || event.Operation == models.OperationRestore {
+			blockerIDs = append(blockerIDs, event.TaskID)
+		}
+		if task, ok := recorded.purged[event.TaskID]; ok && task.ParentID != nil {
+			related = append(related, *task.ParentID)
+		}
+	}
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	var parentIDs []uint
+	if err := tx.Unscoped().Model(&models.Task{}).
+		Where("id IN ? AND parent_id IS NOT NULL", taskIDs).
+		Pluck("parent_id", &parentIDs).Error; err != nil {
+		return err
+	}
+	related = append(related, parentIDs...)
+	if len(blockerIDs) > 0 {
+		var blockedIDs []uint
+		if err := tx.Model(&models.TaskDependency{}).Where("blocked_by_id IN ?", blockerIDs).Pluck("task_id", &blockedIDs).Error; err != nil {
+			return err
+		}
+		related = append(related, blockedIDs...)
+	}
+
+	var touched []uint
+	for _, id := range related {
+		if !changed[id] {
+			changed[id] = true
+			touched = append(touched, id)
+		}
+	}
+	return touchTasks(tx, touched)
+}
+
+// saveTask writes every column of a task and advances its version. The UPDATE only matches
+// while the stored version still equals task.Version, so of two concurrent writers
+// reading the same version exactly one succeeds.
+func saveTask(tx *gorm.DB, task *models.Task) error {
+	current := task.Version
+	task.Version = current + 1
+	result := tx.Model(task).Where("version = ?", current).Select("*").Omit(clause.Associations).Updates(task)
+	return checkVersionedUpdate(task, current, result)
+}
+
+// updateTaskColumns writes the given columns of a task with the same version check as saveTask
+func updateTaskColumns(tx *gorm.DB, task *models.Task, columns map[string]interface{}) error {
+	current := task.Version
+	columns["version"] = current + 1
+	result := tx.Model(&models.Task{}).Where("id = ? AND version = ?", task.ID, current).Updates(columns)
+	if err := checkVersionedUpdate(task, current, result); err != nil {
+		return err
+	}
+	task.Version = current + 1
+	return nil
+}
+
+func checkVersionedUpdate(task *models.Task, current uint, result *gorm.DB) error {
+	if result.Error != nil {
+		task.Version = current
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		task.Version = current
+		return errVersionMismatch
+	}
+	return nil
+}
+
+// requireVersion checks a client's expected version against the task as read
+func requireVersion(task *models.Task, expected *uint) error {
+	if expected != nil && *expected != task.Version {
+		return errVersionMismatch
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"task-manager-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ViewService struct {
+	db *gorm.DB
+}
+
+func NewViewService(db *gorm.DB) *ViewService {
+	return &ViewService{db: db}
+}
+
+// CreateView saves a named task query for a user
+func (s *ViewService) CreateView(userID uint, req *models.SavedViewRequest) (*models.SavedView, error) {
+	if err := s.checkNameAvailable(userID, req.Name, 0); err != nil {
+		return nil, err
+	}
+
+	view := &models.SavedView{UserID: userID, Name: req.Name, Filter: req.Filter, Sort: req.Sort}
+	if err := s.db.Create(view).Error; err != nil {
+		return nil, fmt.Errorf("failed to create view: %w", err)
+	}
+	return view, nil
+}
+
+// GetViews returns a user's saved views ordered by name
+func (s *ViewService) GetViews(userID uint) ([]models.SavedView, error) {
+	views := []models.SavedView{}
+	if err := s.db.Where("user_id = ?", userID).Order("name ASC").Find(&views).Error; err != nil {
+		return nil, fmt.Errorf("failed to get views: %w", err)
+	}
+	return views, nil
+}
+
+// GetView retrieves one of a user's saved views
+func (s *ViewService) GetView(userID, viewID uint) (*models.SavedView, error) {
+	var view models.SavedView
+	err := s.db.Where("id = ? AND user_id = ?", viewID, userID).First(&view).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("view not found")
+		}
+		return nil, fmt.Errorf("failed to get view: %w", err)
+	}
+	return &view, nil
+}
+
+// UpdateView renames a saved view or changes its query
+func (s *ViewService) UpdateView(userID, viewID uint, req *models.UpdateSavedViewRequest) (*models.SavedView, error) {
+	view, err := s.GetView(userID, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name != view.Name {
+		if err := s.checkNameAvailable(userID, *req.Name, view.ID); err != nil {
+			return nil, err
+		}
+		view.Name = *req.Name
+	}
+	if req.Filter != nil {
+		view.Filter = *req.Filter
+	}
+	if req.Sort != nil {
+		view.Sort = *req.Sort
+	}
+
+	if err := s.db.Save(view).Error; err != nil {
+		return nil, fmt.Errorf("failed to update view: %w", err)
+	}
+	return view, nil
+}
+
+// DeleteView deletes a saved view
+func (s *ViewService) DeleteView(userID, viewID uint) error {
+	view, err := s.GetView(userID, viewID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(view).Error; err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	return nil
+}
+
+func (s *ViewService) checkNameAvailable(userID uint, name string, exceptID uint) error {
+	var existing int64
+	err := s.db.Model(&models.SavedView{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).
+		Count(&existing).Error
+	if err != nil {
+		return fmt.Errorf("failed to check view name: %w", err)
+	}
+	if existing > 0 {
+		return errors.New("view already exists")
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/notify"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lastErrorLength bounds the error kept for a failed delivery attempt
+const lastErrorLength = 1000
+
+type WebhookService struct {
+	db                  *gorm.DB
+	client              *http.Client
+	maxAttempts         int
+	allowPrivateTargets bool
+}
+
+// NewWebhookService creates a service that delivers each queued event at most maxAttempts
+// times, waiting up to timeout for the receiver to answer
+func NewWebhookService(db *gorm.DB, timeout time.Duration, maxAttempts int) *WebhookService {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	s := &WebhookService{db: db, maxAttempts: maxAttempts}
+	s.client = s.newWebhookClient(timeout)
+	return s
+}
+
+// SetAllowPrivateTargets lets webhooks point at loopback and private addresses, which are
+// refused by default. It is meant for local development.
+func (s *WebhookService) SetAllowPrivateTargets(allow bool) {
+	s.allowPrivateTargets = allow
+}
+
+// CreateWebhook registers a webhook and generates the secret its deliveries are signed with.
+// The URL must resolve to public addresses only.
+func (s *WebhookService) CreateWebhook(userID uint, req *models.WebhookRequest) (*models.CreatedWebhook, error) {
+	if err := s.checkWebhookURL(context.Background(), req.URL); err != nil {
+		return nil, err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	webhook := models.Webhook{
+		UserID:     userID,
+		URL:        req.URL,
+		Secret:     "whsec_" + secret,
+		EventTypes: req.Events,
+		Active:     req.Active == nil || *req.Active,
+	}
+	if err := s.db.Create(&webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return &models.CreatedWebhook{Webhook: webhook, Secret: webhook.Secret}, nil
+}
+
+// GetWebhooks returns a user's webhooks, oldest first
+func (s *WebhookService) GetWebhooks(userID uint) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+	if err := s.db.Where("user_id = ?", userID).Order("id ASC").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetWebhook retrieves one of a user's webhooks
+func (s *WebhookService) GetWebhook(userID, webhookID uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := s.db.Where("id = ? AND user_id = ?", webhookID, userID).First(&webhook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// UpdateWebhook changes a webhook's URL, event types or whether it is active. A new URL is
+// checked like on creation.
+func (s *WebhookService) UpdateWebhook(userID, webhookID uint, req *models.UpdateWebhookRequest) (*models.Webhook, error) {
+	webhook, err := s.GetWebhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := s.checkWebhookURL(context.Background(), *req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if len(req.Events) > 0 {
+		webhook.EventTypes = req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.db.Save(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook together with its delivery log
+func (s *WebhookService) DeleteWebhook(userID, webhookID uint) error {
+	webhook, err := s.GetWebhook(userID, webhookID)
+	if err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveries returns a webhook's deliveries, newest first
+func (s *WebhookService) GetDeliveries(userID, webhookID uint, filter *models.DeliveryFilter) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.GetWebhook(userID, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deliveries: %w", err)
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	err := query.Order("id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+// RetryDelivery queues a dead delivery again with a fresh set of attempts
+func (s *WebhookService) RetryDelivery(userID, webhookID, deliveryID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	var delivery models.WebhookDelivery
+	err := s.db.Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&delivery).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+	if delivery.Status != models.DeliveryDead {
+		return nil, errors.New("delivery is not dead")
+	}
+
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.db.Save(&delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to retry delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// DeliverDue sends up to limit deliveries that are due, oldest first, and returns how many
+// it attempted. Deliveries of inactive webhooks wait until the webhook is activated again.
+func (s *WebhookService) DeliverDue(ctx context.Context, limit int) (int, error) {
+	var due []models.WebhookDelivery
+	err := s.db.WithContext(ctx).
+		Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id AND webhooks.active = ?", true).
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+		Order("webhook_deliveries.next_attempt_at ASC, webhook_deliveries.id ASC").
+		Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due deliveries: %w", err)
+	}
+
+	attempted := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		claimed, err := s.claim(&due[i])
+		if err != nil {
+			return attempted, err
+		}
+		if !claimed {
+			continue
+		}
+		attempted++
+		if err := s.deliver(ctx, &due[i]); err != nil {
+			return attempted, err
+		}
+	}
+	return attempted, nil
+}
+
+// claim takes a delivery for one attempt. Another server that loaded the same delivery
+// fails to claim it, and a server that dies mid-attempt leaves it to be retried once the
+// attempt has timed out.
+func (s *WebhookService) claim(delivery *models.WebhookDelivery) (bool, error) {
+	now := time.Now()
+	result := s.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.DeliveryPending, delivery.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        delivery.Attempts + 1,
+			"last_attempt_at": now,
+			"next_attempt_at": now.Add(s.client.Timeout + firstRetryDelay),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim delivery: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	return true, nil
+}
+
+// deliver posts a claimed delivery and records the outcome
+func (s *WebhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	var webhook models.Webhook
+	if err := s.db.First(&webhook, delivery.WebhookID).Error; err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	status, sendErr := s.send(ctx, &webhook, delivery)
+	updates := map[string]interface{}{"response_status": nil, "last_error": ""}
+	if status != 0 {
+		updates["response_status"] = status
+	}
+	now := time.Now()
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.DeliverySucceeded
+		updates["delivered_at"] = now
+	case delivery.Attempts >= s.maxAttempts:
+		updates["status"] = models.DeliveryDead
+		updates["last_error"] = truncate(sendErr.Error(), lastErrorLength)
+	default:
+		updates["next_attempt_at"] = now.Add(retryDelay(delivery.Attempts))
+		updates["last_error"] = truncate(sendErr.Error(), lastErrorLength)
+	}
+	if err := s.db.Model(delivery).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record delivery: %w", err)
+	}
+	return nil
+}
+
+// send posts a delivery's payload and returns the response status. Any status other than
+// 2xx counts as a failure.
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "task-manager-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	if delivery.IdempotencyKey != nil {
+		req.Header.Set("Idempotency-Key", *delivery.IdempotencyKey)
+	}
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", "sha256="+WebhookSignature(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// WebhookSignature returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the
+// webhook secret, as sent in the X-Webhook-Signature header
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HandleOutboxEvent queues an outbox event for the active webhooks that selected its type
+// and belong to users who can see the task. An event handled again is not queued twice.
+func (s *WebhookService) HandleOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	if len(event.Audience) == 0 {
+		return nil
+	}
+	return s.queue(ctx, []uint(event.Audience), event.Type, event.IdempotencyKey, event.Payload)
+}
+
+// Notify queues a reminder or digest for the user's active webhooks that selected its type
+func (s *WebhookService) Notify(ctx context.Context, msg *notify.Message) error {
+	payload, err := json.Marshal(struct {
+		Type       string          `json:"type"`
+		OccurredAt time.Time       `json:"occurredAt"`
+		Data       *notify.Message `json:"data"`
+	}{Type: msg.Type, OccurredAt: msg.At, Data: msg})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return s.queue(ctx, []uint{msg.UserID}, msg.Type, msg.Key, models.JSONDocument(payload))
+}
+
+// queue adds a delivery for each active webhook of the users that selected the event type.
+// A webhook gets one delivery per idempotency key.
+func (s *WebhookService) queue(ctx context.Context, userIDs []uint, eventType, key string, payload models.JSONDocument) error {
+	var webhooks []models.Webhook
+	err := s.db.WithContext(ctx).Where("user_id IN ? AND active = ?", userIDs, true).Order("id ASC").Find(&webhooks).Error
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.EventTypes.Includes(eventType) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:      webhook.ID,
+			IdempotencyKey: &key,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err = s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+	if err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
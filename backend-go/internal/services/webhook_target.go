@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// errPrivateWebhookTarget is returned for webhook URLs that point into the server's own network
+var errPrivateWebhookTarget = errors.New("webhook URL must resolve to a public address")
+
+// isPrivateAddress reports whether an address is loopback, link-local, private, multicast
+// or unspecified, none of which a webhook may be sent to
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+// checkWebhookURL rejects a webhook URL whose host is, or resolves to, a private address
+func (s *WebhookService) checkWebhookURL(ctx context.Context, rawURL string) error {
+	if s.allowPrivateTargets {
+		return nil
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return errPrivateWebhookTarget
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, target.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return errPrivateWebhookTarget
+	}
+	for _, addr := range addrs {
+		if isPrivateAddress(addr.IP) {
+			return errPrivateWebhookTarget
+		}
+	}
+	return nil
+}
+
+// newWebhookClient returns the client deliveries are sent with. The host is resolved again
+// for every connection, so each dialed address is checked as well, and redirects are not
+// followed: a 3xx response counts as a failed attempt.
+func (s *WebhookService) newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if s.allowPrivateTargets {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateAddress(ip) {
+				return fmt.Errorf("refusing to connect to %s: %w", address, errPrivateWebhookTarget)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package filter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"task-manager-backend/internal/filter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should bind AND tighter than OR", func(t *testing.T) {
+		expr, err := filter.Parse("status:pending OR priority:high AND tag:work")
+		require.NoError(t, err)
+		or, ok := expr.(*filter.Or)
+		require.True(t, ok)
+		assert.IsType(t, &filter.Condition{}, or.Left)
+		assert.IsType(t, &filter.And{}, or.Right)
+	})
+
+	t.Run("should parse groups, NOT, lists and implicit AND", func(t *testing.T) {
+		expr, err := filter.Parse(`priority:high,medium not (due<2026-11-01 OR title:"weekly report")`)
+		require.NoError(t, err)
+		and, ok := expr.(*filter.And)
+		require.True(t, ok)
+
+		priority := and.Left.(*filter.Condition)
+		assert.Equal(t, filter.FieldPriority, priority.Field)
+		assert.Equal(t, []filter.Value{{Text: "high", At: 10}, {Text: "medium", At: 15}}, priority.Values)
+
+		not, ok := and.Right.(*filter.Not)
+		require.True(t, ok)
+		group := not.Operand.(*filter.Or)
+		title := group.Right.(*filter.Condition)
+		assert.Equal(t, filter.OpMatch, title.Op)
+		assert.Equal(t, "weekly report", title.Values[0].Text)
+	})
+
+	t.Run("should accept RFC 3339 times as values", func(t *testing.T) {
+		expr, err := filter.Parse("created>=2026-10-01T09:30:00Z")
+		require.NoError(t, err)
+		condition := expr.(*filter.Condition)
+		assert.Equal(t, filter.OpGreaterEqual, condition.Op)
+		assert.Equal(t, "2026-10-01T09:30:00Z", condition.Values[0].Text)
+	})
+
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{"", 1, "empty filter"},
+		{"colour:red", 1, `unknown field "colour"`},
+		{"status:pending AND", 19, "expected a condition"},
+		{"status:done", 8, `invalid status value "done"`},
+		{"title<abc", 6, `title does not support "<"`},
+		{"(status:pending", 1, `unclosed "("`},
+		{"status:pending)", 15, `unexpected ")"`},
+		{"due<none", 5, "none only works"},
+		{"due:soon", 5, `invalid due value "soon"`},
+		{`title:"open`, 7, "unterminated string"},
+		{"priority high", 9, `expected ":"`},
+	}
+	for _, tt := range tests {
+		t.Run("should report "+tt.msg, func(t *testing.T) {
+			_, err := filter.Parse(tt.input)
+			var filterErr *filter.Error
+			require.True(t, errors.As(err, &filterErr), "%v", err)
+			assert.Equal(t, tt.pos, filterErr.Pos)
+			assert.Contains(t, filterErr.Message, tt.msg)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) // a Thursday
+	compile := func(input string) (string, []interface{}) {
+		expr, err := filter.Parse(input)
+		require.NoError(t, err)
+		compiled := filter.Compile(expr, filter.Context{Now: now, UserID: 7})
+		return compiled.SQL, compiled.Vars
+	}
+
+	t.Run("should parameterize values", func(t *testing.T) {
+		sql, vars := compile(`status:pending AND title:"50%_off"`)
+		assert.Equal(t, `(tasks.status IN ? AND (LOWER(tasks.title) LIKE ? ESCAPE '\'))`, sql)
+		assert.Equal(t, []interface{}{[]string{"pending"}, `%50\%\_off%`}, vars)
+	})
+
+	t.Run("should resolve periods against the current time", func(t *testing.T) {
+		sql, vars := compile("due:this-week")
+		assert.Equal(t, "(tasks.due_date IS NOT NULL AND tasks.due_date >= ? AND tasks.due_date < ?)", sql)
+		assert.Equal(t, []interface{}{
+			time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		}, vars)
+	})
+
+	t.Run("should compare dates as whole days", func(t *testing.T) {
+		_, vars := compile("created<=2026-11-01")
+		assert.Equal(t, []interface{}{time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)}, vars)
+	})
+
+	t.Run("should resolve me to the current user", func(t *testing.T) {
+		sql, vars := compile("assignee:me,none")
+		assert.Equal(t, "(tasks.assignee_id IS NOT NULL AND tasks.assignee_id = ? OR tasks.assignee_id IS NULL)", sql)
+		assert.Equal(t, []interface{}{uint(7)}, vars)
+	})
+}
@@ -1,19 +1,34 @@
 package handlers_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+	_ "time/tzdata"
 
+	"task-manager-backend/internal/database"
 	"task-manager-backend/internal/handlers"
+	"task-manager-backend/internal/middleware"
 	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/realtime"
 	"task-manager-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func setupTestRouter() *gin.Engine {
@@ -202,6 +217,13 @@ func TestTaskHandlerWithAuth(t *testing.T) {
 			tasks.DELETE("/:id", taskHandler.DeleteTask)
 			tasks.PATCH("/:id/complete", taskHandler.MarkTaskAsCompleted)
 			tasks.PATCH("/:id/pending", taskHandler.MarkTaskAsPending)
+			tasks.PUT("/:id/assignee", taskHandler.AssignTask)
+			tasks.DELETE("/:id/assignee", taskHandler.UnassignTask)
+			tasks.GET("/:id/occurrences", taskHandler.GetOccurrences)
+			tasks.GET("/:id/subtasks", taskHandler.GetSubtasks)
+			tasks.POST("/:id/subtasks", taskHandler.CreateSubtask)
+			tasks.PUT("/:id/subtasks/:subtaskId", taskHandler.UpdateSubtask)
+			tasks.DELETE("/:id/subtasks/:subtaskId", taskHandler.DeleteSubtask)
 		}
 	}
 
@@ -230,6 +252,113 @@ func TestTaskHandlerWithAuth(t *testing.T) {
 			// Should validate query parameters
 			assert.Equal(t, http.StatusBadRequest, w.Code)
 		})
+
+		t.Run("should reject overly long search queries", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks?q="+strings.Repeat("a", 201), nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should reject unknown tag mode", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks?tags=frontend&tagMode=some", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should reject unknown view", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks?view=tree", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("Subtasks with auth", func(t *testing.T) {
+		t.Run("should return 400 for invalid parent ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks/invalid/subtasks", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 for missing title", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/tasks/1/subtasks", bytes.NewBufferString("{}"))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 for invalid subtask ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("DELETE", "/api/v1/tasks/1/subtasks/invalid", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("Assignment with auth", func(t *testing.T) {
+		t.Run("should return 400 for missing assignee", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("PUT", "/api/v1/tasks/1/assignee", bytes.NewBufferString("{}"))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 for invalid task ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("DELETE", "/api/v1/tasks/invalid/assignee", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should reject invalid assignee filters", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks?assignee=someone", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("Occurrences with auth", func(t *testing.T) {
+		t.Run("should return 400 for invalid task ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks/invalid/occurrences", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 for out of range count", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks/1/occurrences?count=500", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
 	})
 
 	t.Run("GetTask with auth", func(t *testing.T) {
@@ -300,3 +429,1262 @@ func TestRequestValidation(t *testing.T) {
 		assert.Equal(t, 10, filter.Limit)
 	})
 }
+
+func TestAuthHandler(t *testing.T) {
+	router := setupTestRouter()
+	mockDB := &gorm.DB{}
+	userService := services.NewUserService(mockDB)
+	tokenService := services.NewTokenService(mockDB, middleware.TokenIssuer{Secret: "test-secret"}, 15*time.Minute, time.Hour)
+	authHandler := handlers.NewAuthHandler(userService, tokenService)
+
+	auth := router.Group("/api/v1/auth")
+	{
+		auth.POST("/register", authHandler.Register)
+		auth.POST("/login", authHandler.Login)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/logout", authHandler.Logout)
+		auth.GET("/me", authHandler.Me)
+	}
+
+	t.Run("Register", func(t *testing.T) {
+		t.Run("should return 400 for invalid JSON", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBufferString("invalid json"))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 for short password", func(t *testing.T) {
+			req := models.RegisterRequest{Email: "jane@example.com", Name: "Jane", Password: "short"}
+			body, _ := json.Marshal(req)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 for invalid email", func(t *testing.T) {
+			req := models.RegisterRequest{Email: "not-an-email", Name: "Jane", Password: "long-enough"}
+			body, _ := json.Marshal(req)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		t.Run("should return 400 for missing password", func(t *testing.T) {
+			body, _ := json.Marshal(gin.H{"email": "jane@example.com"})
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		t.Run("should return 400 without refresh token", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBufferString("{}"))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		t.Run("should return 400 without refresh token", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/auth/logout", bytes.NewBufferString("{}"))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("Me", func(t *testing.T) {
+		t.Run("should return 401 without user context", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/auth/me", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	})
+}
+
+func TestTagHandler(t *testing.T) {
+	router := setupTestRouter()
+	tagService := services.NewTagService(&gorm.DB{}, services.NewTaskService(&gorm.DB{}))
+	tagHandler := handlers.NewTagHandler(tagService)
+
+	tags := router.Group("/api/v1/tags")
+	tags.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	{
+		tags.POST("", tagHandler.CreateTag)
+		tags.PUT("/:id", tagHandler.UpdateTag)
+		tags.DELETE("/:id", tagHandler.DeleteTag)
+	}
+
+	t.Run("CreateTag", func(t *testing.T) {
+		t.Run("should return 400 for missing name", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/tags", bytes.NewBufferString(`{"color":"#ff0000"}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 for invalid color", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/tags", bytes.NewBufferString(`{"name":"frontend","color":"red"}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("UpdateTag", func(t *testing.T) {
+		t.Run("should return 400 for invalid tag ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("PUT", "/api/v1/tags/invalid", bytes.NewBufferString(`{"name":"frontend"}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("DeleteTag", func(t *testing.T) {
+		t.Run("should return 400 for invalid tag ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("DELETE", "/api/v1/tags/invalid", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+}
+
+func TestProjectHandler(t *testing.T) {
+	router := setupTestRouter()
+	projectService := services.NewProjectService(&gorm.DB{}, services.NewTaskService(&gorm.DB{}))
+	projectHandler := handlers.NewProjectHandler(projectService)
+
+	projects := router.Group("/api/v1/projects")
+	projects.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	{
+		projects.POST("", projectHandler.CreateProject)
+		projects.GET("/:id", projectHandler.GetProject)
+		projects.POST("/:id/members", projectHandler.AddMember)
+		projects.DELETE("/:id/members/:userId", projectHandler.RemoveMember)
+	}
+
+	t.Run("CreateProject", func(t *testing.T) {
+		t.Run("should return 400 for missing name", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/projects", bytes.NewBufferString("{}"))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("GetProject", func(t *testing.T) {
+		t.Run("should return 400 for invalid project ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/projects/invalid", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("AddMember", func(t *testing.T) {
+		t.Run("should return 400 for unknown role", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/projects/1/members", bytes.NewBufferString(`{"email":"teammate@example.com","role":"admin"}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("RemoveMember", func(t *testing.T) {
+		t.Run("should return 400 for invalid user ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("DELETE", "/api/v1/projects/1/members/invalid", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+}
+
+func TestCommentHandler(t *testing.T) {
+	router := setupTestRouter()
+	commentService := services.NewCommentService(&gorm.DB{}, services.NewTaskService(&gorm.DB{}))
+	commentHandler := handlers.NewCommentHandler(commentService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	{
+		tasks.GET("/:id/comments", commentHandler.GetComments)
+		tasks.POST("/:id/comments", commentHandler.CreateComment)
+		tasks.PUT("/:id/comments/:commentId", commentHandler.UpdateComment)
+	}
+
+	t.Run("GetComments", func(t *testing.T) {
+		t.Run("should return 400 for out of range limit", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks/1/comments?limit=500", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("CreateComment", func(t *testing.T) {
+		t.Run("should return 400 for empty body", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("POST", "/api/v1/tasks/1/comments", bytes.NewBufferString(`{"body":""}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("UpdateComment", func(t *testing.T) {
+		t.Run("should return 400 for invalid comment ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("PUT", "/api/v1/tasks/1/comments/invalid", bytes.NewBufferString(`{"body":"Edited"}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+}
+
+func TestEventHandler(t *testing.T) {
+	router := setupTestRouter()
+	eventService := services.NewEventService(&gorm.DB{})
+	eventHandler := handlers.NewEventHandler(eventService)
+
+	v1 := router.Group("/api/v1")
+	v1.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	{
+		v1.GET("/tasks/:id/history", eventHandler.GetTaskHistory)
+		v1.GET("/activity", eventHandler.GetActivity)
+	}
+
+	t.Run("GetTaskHistory", func(t *testing.T) {
+		t.Run("should return 400 for invalid task ID", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/tasks/invalid/history", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+
+	t.Run("GetActivity", func(t *testing.T) {
+		t.Run("should return 400 for malformed time", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/activity?from=yesterday", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("should return 400 when to is not after from", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/activity?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+}
+
+// newTestDB opens a migrated in-memory SQLite database
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, database.Migrate(db))
+	return db
+}
+
+func TestTaskConditionalRequests(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	{
+		tasks.GET("/:id", taskHandler.GetTask)
+		tasks.PUT("/:id", taskHandler.UpdateTask)
+	}
+
+	task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Conditional"})
+	require.NoError(t, err)
+	path := "/api/v1/tasks/" + strconv.FormatUint(uint64(task.ID), 10)
+
+	send := func(method, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			httpReq.Header.Set(name, value)
+		}
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("should return the version as an ETag", func(t *testing.T) {
+		w := send("GET", "", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("should return 304 when the ETag still matches", func(t *testing.T) {
+		w := send("GET", "", map[string]string{"If-None-Match": `"1"`})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("should apply an update with a matching If-Match", func(t *testing.T) {
+		w := send("PUT", `{"title":"First edit"}`, map[string]string{"If-Match": `"1"`})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("should return 412 for a stale If-Match", func(t *testing.T) {
+		w := send("PUT", `{"title":"Second tab"}`, map[string]string{"If-Match": `"1"`})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		w = send("PUT", `{"title":"Weak tag"}`, map[string]string{"If-Match": `W/"2"`})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		w = send("GET", "", map[string]string{"If-None-Match": `"1"`})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "First edit")
+	})
+}
+
+func TestPatchTask(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	tasks.PATCH("/:id", taskHandler.PatchTask)
+
+	description := "Write the release notes"
+	dueDate := time.Now().Add(24 * time.Hour)
+	task, err := taskService.CreateTask(1, &models.CreateTaskRequest{
+		Title:       "Release",
+		Description: &description,
+		DueDate:     &dueDate,
+		Tags:        []string{"docs"},
+	})
+	require.NoError(t, err)
+	path := "/api/v1/tasks/" + strconv.FormatUint(uint64(task.ID), 10)
+
+	send := func(contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PATCH", path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("should clear fields set to null in a merge patch", func(t *testing.T) {
+		w := send("application/merge-patch+json", `{"description":null,"dueDate":null,"priority":"high"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var updated models.Task
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		assert.Nil(t, updated.Description)
+		assert.Nil(t, updated.DueDate)
+		assert.Equal(t, models.PriorityHigh, updated.Priority)
+		assert.Equal(t, "Release", updated.Title)
+		require.Len(t, updated.Tags, 1)
+	})
+
+	t.Run("should apply a JSON patch guarded by a test", func(t *testing.T) {
+		w := send("application/json-patch+json", `[{"op":"test","path":"/title","value":"Release"},{"op":"add","path":"/tags/-","value":"release"}]`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var updated models.Task
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		require.Len(t, updated.Tags, 2)
+	})
+
+	t.Run("should return 409 when a test fails", func(t *testing.T) {
+		w := send("application/json-patch+json", `[{"op":"test","path":"/title","value":"Other"},{"op":"replace","path":"/title","value":"Changed"}]`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should validate the patched task", func(t *testing.T) {
+		w := send("application/merge-patch+json", `{"title":null}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send("application/merge-patch+json", `{"priority":"urgent"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = send("application/json-patch+json", `[{"op":"add","path":"/userId","value":2}]`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 415 for other content types", func(t *testing.T) {
+		w := send("application/json", `{"title":"Plain"}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Accept-Patch"))
+	})
+}
+
+func TestRunBatch(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	tasks.POST("/batch", taskHandler.RunBatch)
+
+	existing, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Existing"})
+	require.NoError(t, err)
+
+	send := func(body string) (int, []models.BatchResult, bool) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/tasks/batch", bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+
+		var response struct {
+			Results   []models.BatchResult `json:"results"`
+			Committed bool                 `json:"committed"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Results, response.Committed
+	}
+	id := strconv.FormatUint(uint64(existing.ID), 10)
+
+	t.Run("should return 400 for an empty batch", func(t *testing.T) {
+		code, _, _ := send(`{"operations":[]}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("should not run an atomic batch with an invalid operation", func(t *testing.T) {
+		code, results, committed := send(`{"operations":[{"op":"complete","id":` + id + `},{"op":"create","task":{"title":""}}]}`)
+		assert.Equal(t, http.StatusMultiStatus, code)
+		assert.False(t, committed)
+		require.Len(t, results, 2)
+		assert.Equal(t, http.StatusFailedDependency, results[0].Status)
+		assert.Equal(t, http.StatusBadRequest, results[1].Status)
+	})
+
+	t.Run("should report per-item statuses in best-effort mode", func(t *testing.T) {
+		code, results, committed := send(`{"mode":"bestEffort","operations":[
+			{"op":"create","task":{"title":"New"}},
+			{"op":"complete","id":` + id + `},
+			{"op":"delete","id":9999},
+			{"op":"archive","id":` + id + `}
+		]}`)
+		assert.Equal(t, http.StatusMultiStatus, code)
+		assert.True(t, committed)
+		require.Len(t, results, 4)
+		assert.Equal(t, http.StatusCreated, results[0].Status)
+		require.NotNil(t, results[0].Task)
+		assert.Equal(t, "New", results[0].Task.Title)
+		assert.Equal(t, http.StatusOK, results[1].Status)
+		assert.Equal(t, http.StatusNotFound, results[2].Status)
+		assert.Equal(t, http.StatusBadRequest, results[3].Status)
+	})
+}
+
+func TestTaskListPagination(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	tasks.GET("", taskHandler.GetTasks)
+
+	for _, title := range []string{"First", "Second", "Third"} {
+		_, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: title})
+		require.NoError(t, err)
+	}
+
+	list := func(query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/tasks"+query, nil)
+		router.ServeHTTP(w, httpReq)
+
+		var response struct {
+			Pagination map[string]interface{} `json:"pagination"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response.Pagination
+	}
+
+	t.Run("should link to the next page", func(t *testing.T) {
+		w, pagination := list("?limit=2&status=pending")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, float64(3), pagination["total"])
+		cursor, ok := pagination["nextCursor"].(string)
+		require.True(t, ok)
+		assert.NotContains(t, pagination, "prevCursor")
+		assert.Equal(t, `</api/v1/tasks?cursor=`+cursor+`&limit=2&status=pending>; rel="next"`, w.Header().Get("Link"))
+
+		w, pagination = list("?limit=2&status=pending&cursor=" + cursor)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, pagination, "total")
+		assert.NotContains(t, pagination, "nextCursor")
+		assert.Contains(t, pagination, "prevCursor")
+		assert.Contains(t, w.Header().Get("Link"), `rel="prev"`)
+	})
+
+	t.Run("should return 400 for unusable cursors", func(t *testing.T) {
+		for _, query := range []string{"?cursor=bogus", "?cursor=bogus&page=2", "?cursor=bogus&q=first"} {
+			w, _ := list(query)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
+
+func TestTaskSortAndFields(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	tasks.GET("", taskHandler.GetTasks)
+
+	for _, title := range []string{"Beta", "Alpha"} {
+		_, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: title, Tags: []string{"work"}})
+		require.NoError(t, err)
+	}
+
+	list := func(query string) (int, []map[string]interface{}) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/tasks"+query, nil)
+		router.ServeHTTP(w, httpReq)
+
+		var response struct {
+			Tasks []map[string]interface{} `json:"tasks"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Tasks
+	}
+
+	t.Run("should return only the requested fields in the requested order", func(t *testing.T) {
+		code, tasks := list("?sort=title&fields=id,title")
+		assert.Equal(t, http.StatusOK, code)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, "Alpha", tasks[0]["title"])
+		assert.Len(t, tasks[0], 2)
+		assert.Contains(t, tasks[0], "id")
+	})
+
+	t.Run("should return 400 for unknown sort fields and fields", func(t *testing.T) {
+		for _, query := range []string{"?sort=password", "?sort=title,-title", "?fields=id,secret", "?sort=title%20DESC"} {
+			code, _ := list(query)
+			assert.Equal(t, http.StatusBadRequest, code, query)
+		}
+	})
+
+	t.Run("should filter with an expression", func(t *testing.T) {
+		code, tasks := list("?filter=" + url.QueryEscape(`title:alp OR (tag:home AND status:pending)`))
+		assert.Equal(t, http.StatusOK, code)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "Alpha", tasks[0]["title"])
+	})
+
+	t.Run("should return 400 with the position of a filter error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/tasks?filter="+url.QueryEscape("status:pending AND colour:red"), nil)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Invalid filter", response["error"])
+		assert.Equal(t, float64(20), response["position"])
+	})
+}
+
+func TestViewHandler(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	viewHandler := handlers.NewViewHandler(services.NewViewService(db), taskService)
+
+	views := router.Group("/api/v1/views")
+	views.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	views.POST("", viewHandler.CreateView)
+	views.GET("", viewHandler.GetViews)
+	views.PUT("/:id", viewHandler.UpdateView)
+	views.DELETE("/:id", viewHandler.DeleteView)
+	views.GET("/:id/tasks", viewHandler.GetViewTasks)
+
+	high := models.PriorityHigh
+	today := time.Now()
+	urgent, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Urgent", Priority: &high, DueDate: &today})
+	require.NoError(t, err)
+	_, err = taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Someday"})
+	require.NoError(t, err)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+	taskTitles := func(w *httptest.ResponseRecorder) []string {
+		var response struct {
+			Tasks []models.Task `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		titles := []string{}
+		for _, task := range response.Tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	t.Run("should run a saved view", func(t *testing.T) {
+		w := send("POST", "/api/v1/views", `{"name":"Important","filter":{"filter":"priority:high"},"sort":"title"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var view models.SavedView
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+
+		w = send("GET", "/api/v1/views/"+strconv.FormatUint(uint64(view.ID), 10)+"/tasks?limit=5", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"Urgent"}, taskTitles(w))
+	})
+
+	t.Run("should run built-in views", func(t *testing.T) {
+		w := send("GET", "/api/v1/views/today/tasks", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{urgent.Title}, taskTitles(w))
+
+		w = send("GET", "/api/v1/views", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"high-priority-this-week"`)
+	})
+
+	t.Run("should reject invalid queries", func(t *testing.T) {
+		w := send("POST", "/api/v1/views", `{"name":"Broken","filter":{"filter":"due<whenever"}}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"position":5`)
+
+		w = send("POST", "/api/v1/views", `{"name":"Broken","sort":"-secret"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should not change built-in views", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send("PUT", "/api/v1/views/today", `{"name":"Mine"}`).Code)
+		assert.Equal(t, http.StatusForbidden, send("DELETE", "/api/v1/views/overdue", "").Code)
+	})
+
+	t.Run("should return 404 for unknown views", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, send("GET", "/api/v1/views/9999/tasks", "").Code)
+		assert.Equal(t, http.StatusBadRequest, send("GET", "/api/v1/views/tomorrow/tasks", "").Code)
+	})
+}
+
+func TestDependencyHandler(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	tasks.GET("/plan", taskHandler.GetPlan)
+	tasks.GET("/:id", taskHandler.GetTask)
+	tasks.PATCH("/:id/complete", taskHandler.MarkTaskAsCompleted)
+	tasks.GET("/:id/dependencies", taskHandler.GetDependencies)
+	tasks.POST("/:id/dependencies", taskHandler.AddDependency)
+	tasks.DELETE("/:id/dependencies/:blockedById", taskHandler.RemoveDependency)
+
+	first, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "First"})
+	require.NoError(t, err)
+	second, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Second"})
+	require.NoError(t, err)
+	firstID := strconv.FormatUint(uint64(first.ID), 10)
+	secondID := strconv.FormatUint(uint64(second.ID), 10)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("should add a blocker and flag the task", func(t *testing.T) {
+		w := send("POST", "/api/v1/tasks/"+secondID+"/dependencies", `{"blockedById":`+firstID+`}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = send("GET", "/api/v1/tasks/"+secondID, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"isBlocked":true`)
+	})
+
+	t.Run("should reject a cycle", func(t *testing.T) {
+		w := send("POST", "/api/v1/tasks/"+firstID+"/dependencies", `{"blockedById":`+secondID+`}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should return the plan", func(t *testing.T) {
+		w := send("GET", "/api/v1/tasks/plan?ids="+secondID+","+firstID, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var plan models.Plan
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &plan))
+		require.Len(t, plan.Stages, 2)
+		assert.Equal(t, first.ID, plan.Stages[0][0].ID)
+
+		assert.Equal(t, http.StatusBadRequest, send("GET", "/api/v1/tasks/plan?ids=1,x", "").Code)
+	})
+
+	t.Run("should only complete a blocked task when forced", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, send("PATCH", "/api/v1/tasks/"+secondID+"/complete", "").Code)
+		assert.Equal(t, http.StatusOK, send("PATCH", "/api/v1/tasks/"+secondID+"/complete?force=true", "").Code)
+	})
+
+	t.Run("should remove a blocker", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send("DELETE", "/api/v1/tasks/"+secondID+"/dependencies/"+firstID, "").Code)
+		assert.Equal(t, http.StatusNotFound, send("DELETE", "/api/v1/tasks/"+secondID+"/dependencies/"+firstID, "").Code)
+	})
+}
+
+func TestTrashHandler(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+
+	tasks := router.Group("/api/v1/tasks")
+	tasks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	tasks.GET("/trash", taskHandler.GetTrash)
+	tasks.GET("/:id", taskHandler.GetTask)
+	tasks.DELETE("/:id", taskHandler.DeleteTask)
+	tasks.POST("/:id/restore", taskHandler.RestoreTask)
+
+	task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Trash me"})
+	require.NoError(t, err)
+	path := "/api/v1/tasks/" + strconv.FormatUint(uint64(task.ID), 10)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("should move a deleted task to the trash", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send("DELETE", path).Code)
+		assert.Equal(t, http.StatusNotFound, send("GET", path).Code)
+
+		w := send("GET", "/api/v1/tasks/trash")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"title":"Trash me"`)
+		assert.Contains(t, w.Body.String(), `"deletedAt":`)
+	})
+
+	t.Run("should restore a task", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send("POST", path+"/restore").Code)
+		assert.Equal(t, http.StatusOK, send("GET", path).Code)
+		assert.Equal(t, http.StatusConflict, send("POST", path+"/restore").Code)
+	})
+
+	t.Run("should delete permanently", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send("DELETE", path+"?permanent=true").Code)
+		assert.Equal(t, http.StatusNotFound, send("POST", path+"/restore").Code)
+		assert.NotContains(t, send("GET", "/api/v1/tasks/trash").Body.String(), "Trash me")
+	})
+}
+
+func TestStreamHandler(t *testing.T) {
+	db := newTestDB(t)
+	bus := realtime.NewBus(10, 10)
+	taskService := services.NewTaskService(db)
+	taskService.SetPublisher(bus)
+
+	sessions := &fakeSessions{}
+	sessions.active.Store(true)
+	var tokenExpiresAt atomic.Value
+	tokenExpiresAt.Store(time.Now().Add(time.Hour))
+
+	router := setupTestRouter()
+	authenticated := func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Set("sessionID", "session-1")
+		c.Set("tokenExpiresAt", tokenExpiresAt.Load().(time.Time))
+		c.Next()
+	}
+	streamHandler := handlers.NewStreamHandler(bus, sessions, middleware.TokenIssuer{Secret: "test-secret"}, time.Second)
+	router.GET("/api/v1/events", authenticated, streamHandler.Stream)
+	router.POST("/api/v1/events/token", authenticated, streamHandler.IssueToken)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	connect := func(lastEventID string) (*bufio.Reader, func()) {
+		httpReq, err := http.NewRequest("GET", server.URL+"/api/v1/events", nil)
+		require.NoError(t, err)
+		if lastEventID != "" {
+			httpReq.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(httpReq)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		return bufio.NewReader(resp.Body), func() { resp.Body.Close() }
+	}
+	// next reads the stream up to the next event or comment
+	next := func(stream *bufio.Reader) string {
+		var lines []string
+		for {
+			line, err := stream.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				if len(lines) == 0 || strings.HasPrefix(lines[0], "retry:") {
+					lines = nil
+					continue
+				}
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	t.Run("should stream task changes and heartbeats", func(t *testing.T) {
+		stream, disconnect := connect("")
+		defer disconnect()
+
+		_, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Live"})
+		require.NoError(t, err)
+		event := next(stream)
+		assert.True(t, strings.HasPrefix(event, "id: 1\nevent: task.created\ndata: "), event)
+		assert.Contains(t, event, `"title":"Live"`)
+
+		assert.Equal(t, ": heartbeat", next(stream))
+	})
+
+	t.Run("should resume after the last event ID", func(t *testing.T) {
+		_, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Missed"})
+		require.NoError(t, err)
+
+		stream, disconnect := connect("1")
+		defer disconnect()
+		event := next(stream)
+		assert.True(t, strings.HasPrefix(event, "id: 2\nevent: task.created"), event)
+		assert.Contains(t, event, `"title":"Missed"`)
+	})
+
+	t.Run("should ask clients to resync after a gap", func(t *testing.T) {
+		stream, disconnect := connect("42")
+		defer disconnect()
+		assert.Equal(t, "id: 2\nevent: resync\ndata: {}", next(stream))
+	})
+
+	t.Run("should disconnect when the access token expires", func(t *testing.T) {
+		tokenExpiresAt.Store(time.Now().Add(100 * time.Millisecond))
+		defer tokenExpiresAt.Store(time.Now().Add(time.Hour))
+		stream, disconnect := connect("")
+		defer disconnect()
+		assert.Equal(t, "event: disconnect\ndata: {\"reason\":\"token expired\"}", next(stream))
+	})
+
+	t.Run("should issue stream tokens for the current session", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/api/v1/events/token", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var body struct {
+			Token string `json:"token"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		claims := &middleware.Claims{}
+		_, err = jwt.ParseWithClaims(body.Token, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte("test-secret"), nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, middleware.StreamScope, claims.Scope)
+		assert.Equal(t, "session-1", claims.SessionID)
+		assert.Equal(t, tokenExpiresAt.Load().(time.Time).Unix(), claims.ExpiresAt.Unix())
+	})
+
+	t.Run("should disconnect when the session is revoked", func(t *testing.T) {
+		stream, disconnect := connect("")
+		defer disconnect()
+		sessions.active.Store(false)
+		defer sessions.active.Store(true)
+		assert.Equal(t, "event: disconnect\ndata: {\"reason\":\"session revoked\"}", next(stream))
+	})
+}
+
+// fakeSessions is a SessionChecker whose single session can be revoked from a test
+type fakeSessions struct {
+	active atomic.Bool
+}
+
+func (s *fakeSessions) IsSessionActive(sessionID string) (bool, error) {
+	return s.active.Load(), nil
+}
+
+func TestWebhookHandler(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	webhookService := services.NewWebhookService(db, 5*time.Second, 3)
+	// The test receiver listens on loopback
+	webhookService.SetAllowPrivateTargets(true)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	relay := services.NewOutboxRelay(db, 3)
+	relay.AddSink("webhooks", webhookService)
+
+	webhooks := router.Group("/api/v1/webhooks")
+	webhooks.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	webhooks.POST("", webhookHandler.CreateWebhook)
+	webhooks.GET("", webhookHandler.GetWebhooks)
+	webhooks.GET("/:id", webhookHandler.GetWebhook)
+	webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+	webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+	webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+	webhooks.POST("/:id/deliveries/:deliveryId/retry", webhookHandler.RetryDelivery)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, path, strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	var created struct {
+		ID     uint   `json:"id"`
+		Secret string `json:"secret"`
+	}
+
+	t.Run("should register a webhook and show its secret once", func(t *testing.T) {
+		w := send("POST", "/api/v1/webhooks", `{"url":"https://ci.example.com/hook","events":["task.completed"]}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+
+		w = send("GET", "/api/v1/webhooks", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"url":"https://ci.example.com/hook"`)
+		assert.NotContains(t, w.Body.String(), created.Secret)
+	})
+
+	t.Run("should reject invalid webhooks", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v1/webhooks", `{"url":"ftp://example.com","events":["task.completed"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v1/webhooks", `{"url":"https://example.com","events":["task.exploded"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v1/webhooks", `{"url":"https://example.com","events":[]}`).Code)
+
+		webhookService.SetAllowPrivateTargets(false)
+		defer webhookService.SetAllowPrivateTargets(true)
+		w := send("POST", "/api/v1/webhooks", `{"url":"http://169.254.169.254/latest","events":["task.completed"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "public address")
+	})
+
+	path := "/api/v1/webhooks/" + strconv.FormatUint(uint64(created.ID), 10)
+
+	t.Run("should list deliveries", func(t *testing.T) {
+		task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Deploy"})
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(1, task.ID)
+		require.NoError(t, err)
+		_, err = relay.RelayDue(context.Background(), 10)
+		require.NoError(t, err)
+
+		w := send("GET", path+"/deliveries", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Deliveries []models.WebhookDelivery `json:"deliveries"`
+			Pagination struct {
+				Total int64 `json:"total"`
+			} `json:"pagination"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Deliveries, 1)
+		assert.Equal(t, int64(1), resp.Pagination.Total)
+		assert.Equal(t, "task.completed", resp.Deliveries[0].EventType)
+		assert.Contains(t, w.Body.String(), `"payload":{"eventId":`)
+
+		assert.Equal(t, http.StatusBadRequest, send("GET", path+"/deliveries?status=lost", "").Code)
+		retry := path + "/deliveries/" + strconv.FormatUint(uint64(resp.Deliveries[0].ID), 10) + "/retry"
+		assert.Equal(t, http.StatusConflict, send("POST", retry, "").Code)
+	})
+
+	t.Run("should update and delete a webhook", func(t *testing.T) {
+		w := send("PUT", path, `{"active":false}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"active":false`)
+
+		assert.Equal(t, http.StatusOK, send("DELETE", path, "").Code)
+		assert.Equal(t, http.StatusNotFound, send("GET", path, "").Code)
+		assert.Equal(t, http.StatusNotFound, send("GET", path+"/deliveries", "").Code)
+	})
+}
+
+func TestReminderHandler(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	taskService := services.NewTaskService(db)
+	taskHandler := handlers.NewTaskHandler(taskService)
+	notificationHandler := handlers.NewNotificationHandler(services.NewNotificationService(db))
+
+	api := router.Group("/api/v1")
+	api.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	api.GET("/tasks/:id/reminders", taskHandler.GetReminders)
+	api.POST("/tasks/:id/reminders", taskHandler.AddReminder)
+	api.DELETE("/tasks/:id/reminders/:reminderId", taskHandler.RemoveReminder)
+	api.GET("/notifications/settings", notificationHandler.GetSettings)
+	api.PUT("/notifications/settings", notificationHandler.UpdateSettings)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, path, strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	due := time.Date(2030, 1, 15, 9, 0, 0, 0, time.UTC)
+	task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Renew passport", DueDate: &due})
+	require.NoError(t, err)
+	path := "/api/v1/tasks/" + strconv.FormatUint(uint64(task.ID), 10) + "/reminders"
+
+	t.Run("should add, list and remove reminders", func(t *testing.T) {
+		w := send("POST", path, `{"offsetMinutes":1440}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var reminder models.TaskReminder
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reminder))
+		require.NotNil(t, reminder.RemindAt)
+		assert.True(t, reminder.RemindAt.Equal(due.Add(-24*time.Hour)))
+
+		assert.Equal(t, http.StatusConflict, send("POST", path, `{"offsetMinutes":1440}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send("POST", path, `{"offsetMinutes":-5}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send("POST", path, `{}`).Code)
+		assert.Equal(t, http.StatusNotFound, send("POST", "/api/v1/tasks/9999/reminders", `{"offsetMinutes":0}`).Code)
+
+		w = send("GET", path, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"offsetMinutes":1440`)
+
+		reminderPath := path + "/" + strconv.FormatUint(uint64(reminder.ID), 10)
+		assert.Equal(t, http.StatusOK, send("DELETE", reminderPath, "").Code)
+		assert.Equal(t, http.StatusNotFound, send("DELETE", reminderPath, "").Code)
+	})
+
+	t.Run("should read and change notification settings", func(t *testing.T) {
+		w := send("GET", "/api/v1/notifications/settings", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"channels":["inapp"]`)
+
+		w = send("PUT", "/api/v1/notifications/settings", `{"channels":["inapp","email"],"digestEnabled":true,"digestHour":7,"timezone":"America/New_York"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"digestHour":7`)
+		assert.Contains(t, w.Body.String(), `"timezone":"America/New_York"`)
+
+		assert.Equal(t, http.StatusBadRequest, send("PUT", "/api/v1/notifications/settings", `{"channels":["pager"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send("PUT", "/api/v1/notifications/settings", `{"digestHour":24}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send("PUT", "/api/v1/notifications/settings", `{"timezone":"Nowhere/City"}`).Code)
+	})
+}
+
+func TestNotificationHandler(t *testing.T) {
+	db := newTestDB(t)
+
+	router := setupTestRouter()
+	notificationHandler := handlers.NewNotificationHandler(services.NewNotificationService(db))
+
+	notifications := router.Group("/api/v1/notifications")
+	notifications.Use(func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Next()
+	})
+	notifications.GET("", notificationHandler.GetNotifications)
+	notifications.PATCH("/read", notificationHandler.MarkAllRead)
+	notifications.PATCH("/:id/read", notificationHandler.MarkRead)
+	notifications.GET("/settings", notificationHandler.GetSettings)
+	notifications.PUT("/settings", notificationHandler.UpdateSettings)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(method, path, strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	stored := []models.Notification{
+		{UserID: 1, Key: "task-event-1", Type: "task.assigned", Title: `Ana assigned "Plan sprint" to you`},
+		{UserID: 1, Key: "task-event-2", Type: "task.completed", Title: `Ana completed "Plan sprint"`},
+		{UserID: 2, Key: "task-event-3", Type: "task.completed", Title: `Ana completed "Other"`},
+	}
+	require.NoError(t, db.Create(&stored).Error)
+
+	type inbox struct {
+		Notifications []models.Notification `json:"notifications"`
+		UnreadCount   int64                 `json:"unreadCount"`
+		Pagination    struct {
+			Total int64 `json:"total"`
+		} `json:"pagination"`
+	}
+	get := func(path string) inbox {
+		w := send("GET", path, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp inbox
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("should list notifications with the unread count", func(t *testing.T) {
+		resp := get("/api/v1/notifications")
+		require.Len(t, resp.Notifications, 2)
+		assert.Equal(t, int64(2), resp.UnreadCount)
+		assert.Equal(t, stored[1].ID, resp.Notifications[0].ID)
+		assert.Equal(t, http.StatusBadRequest, send("GET", "/api/v1/notifications?limit=500", "").Code)
+	})
+
+	t.Run("should mark one or all notifications read", func(t *testing.T) {
+		w := send("PATCH", "/api/v1/notifications/"+strconv.FormatUint(uint64(stored[0].ID), 10)+"/read", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"readAt":"`)
+		assert.Equal(t, http.StatusNotFound, send("PATCH", "/api/v1/notifications/"+strconv.FormatUint(uint64(stored[2].ID), 10)+"/read", "").Code)
+
+		resp := get("/api/v1/notifications?unread=true")
+		require.Len(t, resp.Notifications, 1)
+		assert.Equal(t, int64(1), resp.UnreadCount)
+
+		w = send("PATCH", "/api/v1/notifications/read", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"marked":1`)
+		assert.Zero(t, get("/api/v1/notifications").UnreadCount)
+	})
+
+	t.Run("should mute notification types", func(t *testing.T) {
+		w := send("PUT", "/api/v1/notifications/settings", `{"mutedTypes":["task.updated","task.overdue"]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"mutedTypes":["task.updated","task.overdue"]`)
+		assert.Equal(t, http.StatusBadRequest, send("PUT", "/api/v1/notifications/settings", `{"mutedTypes":["task.exploded"]}`).Code)
+	})
+}
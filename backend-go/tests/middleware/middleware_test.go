@@ -1,8 +1,21 @@
 package middleware_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -227,3 +240,424 @@ func TestClaims(t *testing.T) {
 		assert.Equal(t, uint(456), parsedClaims.UserID)
 	})
 }
+
+func TestGenerateToken(t *testing.T) {
+	t.Run("should generate token accepted by AuthMiddleware", func(t *testing.T) {
+		secret := "test-secret"
+		tokenString, expiresAt, err := middleware.GenerateToken(secret, 789, "session-1", time.Hour)
+		assert.NoError(t, err)
+		assert.True(t, expiresAt.After(time.Now()))
+
+		router := setupTestRouter()
+		router.Use(middleware.AuthMiddleware(secret))
+		router.GET("/protected", func(c *gin.Context) {
+			userID, _ := middleware.GetUserIDFromContext(c)
+			c.JSON(200, gin.H{"userID": userID})
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "789")
+	})
+
+	t.Run("should reject token signed with another secret", func(t *testing.T) {
+		tokenString, _, err := middleware.GenerateToken("other-secret", 789, "", time.Hour)
+		assert.NoError(t, err)
+
+		_, err = jwt.ParseWithClaims(tokenString, &middleware.Claims{}, func(token *jwt.Token) (interface{}, error) {
+			return []byte("test-secret"), nil
+		})
+		assert.Error(t, err)
+	})
+}
+
+// mockSessionChecker reports sessions as active unless listed in revoked
+type mockSessionChecker struct {
+	revoked map[string]bool
+}
+
+func (m *mockSessionChecker) IsSessionActive(sessionID string) (bool, error) {
+	return !m.revoked[sessionID], nil
+}
+
+func TestAuthMiddlewareWithSessions(t *testing.T) {
+	secret := "test-secret"
+	sessions := &mockSessionChecker{revoked: map[string]bool{"revoked-session": true}}
+	router := setupTestRouter()
+	router.Use(middleware.NewAuthMiddleware(middleware.AuthConfig{
+		Keys:     middleware.NewHMACKeyProvider(secret),
+		Sessions: sessions,
+	}))
+	router.GET("/protected", func(c *gin.Context) {
+		expiresAt, _ := middleware.GetTokenExpiryFromContext(c)
+		c.JSON(200, gin.H{"sessionID": c.GetString("sessionID"), "expiresAt": expiresAt.Unix()})
+	})
+
+	t.Run("should accept token with active session", func(t *testing.T) {
+		tokenString, expiresAt, err := middleware.GenerateToken(secret, 1, "active-session", time.Hour)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "active-session")
+		assert.Contains(t, w.Body.String(), `"expiresAt":`+strconv.FormatInt(expiresAt.Unix(), 10))
+	})
+
+	t.Run("should reject token with revoked session", func(t *testing.T) {
+		tokenString, _, err := middleware.GenerateToken(secret, 1, "revoked-session", time.Hour)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should accept token without session ID", func(t *testing.T) {
+		tokenString, _, err := middleware.GenerateToken(secret, 1, "", time.Hour)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+// signToken signs claims for user 42 with the given method, key and kid
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, mutate func(*middleware.Claims)) string {
+	claims := &middleware.Claims{
+		UserID: 42,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if mutate != nil {
+		mutate(claims)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenString, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return tokenString
+}
+
+// doAuthRequest sends a request with the bearer token through the middleware and returns the status code
+func doAuthRequest(handler gin.HandlerFunc, tokenString string) int {
+	router := setupTestRouter()
+	router.Use(handler)
+	router.GET("/protected", func(c *gin.Context) {
+		userID, _ := middleware.GetUserIDFromContext(c)
+		c.JSON(200, gin.H{"userID": userID})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func publicKeyPEM(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) middleware.JWK {
+	return middleware.JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestAlgorithmAllowList(t *testing.T) {
+	secret := "test-secret"
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	pemProvider, err := middleware.NewPEMKeyProvider(map[string][]byte{"rsa-1": publicKeyPEM(t, &rsaKey.PublicKey)})
+	assert.NoError(t, err)
+
+	t.Run("should reject HS256 token when only RS256 is allowed", func(t *testing.T) {
+		handler := middleware.NewAuthMiddleware(middleware.AuthConfig{
+			Keys:       middleware.ChainKeyProvider{middleware.NewHMACKeyProvider(secret), pemProvider},
+			Algorithms: []string{"RS256"},
+		})
+		tokenString := signToken(t, jwt.SigningMethodHS256, []byte(secret), "", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should reject unsigned tokens", func(t *testing.T) {
+		handler := middleware.AuthMiddleware(secret)
+		tokenString := signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should reject HS256 token signed with the RSA public key", func(t *testing.T) {
+		handler := middleware.NewAuthMiddleware(middleware.AuthConfig{
+			Keys:       pemProvider,
+			Algorithms: []string{"HS256", "RS256"},
+		})
+		tokenString := signToken(t, jwt.SigningMethodHS256, publicKeyPEM(t, &rsaKey.PublicKey), "rsa-1", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(handler, tokenString))
+	})
+}
+
+func TestPEMKeyProvider(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	provider, err := middleware.NewPEMKeyProvider(map[string][]byte{
+		"rsa-1": publicKeyPEM(t, &rsaKey.PublicKey),
+		"ec-1":  publicKeyPEM(t, &ecKey.PublicKey),
+	})
+	assert.NoError(t, err)
+
+	handler := middleware.NewAuthMiddleware(middleware.AuthConfig{
+		Keys:       provider,
+		Algorithms: []string{"RS256", "ES256"},
+	})
+
+	t.Run("should accept RS256 token", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", nil)
+		assert.Equal(t, http.StatusOK, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should accept ES256 token", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodES256, ecKey, "ec-1", nil)
+		assert.Equal(t, http.StatusOK, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should reject token with unknown kid", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodRS256, rsaKey, "unknown", nil)
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should reject token whose kid points at a key of another type", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodRS256, rsaKey, "ec-1", nil)
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should load key from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "public.pem")
+		assert.NoError(t, os.WriteFile(path, publicKeyPEM(t, &rsaKey.PublicKey), 0o600))
+
+		fileProvider, err := middleware.LoadPEMKeyFile(path, "")
+		assert.NoError(t, err)
+
+		key, err := fileProvider.VerificationKey("", "RS256")
+		assert.NoError(t, err)
+		assert.Equal(t, &rsaKey.PublicKey, key)
+	})
+}
+
+func TestJWKSKeyProvider(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	var fetches int32
+	var rotated atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		set := middleware.JWKS{Keys: []middleware.JWK{rsaJWK("old", &oldKey.PublicKey)}}
+		if rotated.Load() {
+			set.Keys = append(set.Keys, rsaJWK("new", &newKey.PublicKey))
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	defer server.Close()
+
+	provider := middleware.NewJWKSKeyProvider(server.URL, time.Hour)
+	provider.SetMinRefreshInterval(0)
+	handler := middleware.NewAuthMiddleware(middleware.AuthConfig{
+		Keys:       provider,
+		Algorithms: []string{"RS256"},
+		Issuer:     "https://idp.example.com",
+		Audience:   "task-api",
+	})
+	withIssuer := func(claims *middleware.Claims) {
+		claims.Issuer = "https://idp.example.com"
+		claims.Audience = jwt.ClaimStrings{"task-api"}
+	}
+
+	t.Run("should accept token signed by key in the set", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodRS256, oldKey, "old", withIssuer)
+		assert.Equal(t, http.StatusOK, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should serve repeated lookups from the cache", func(t *testing.T) {
+		before := atomic.LoadInt32(&fetches)
+		tokenString := signToken(t, jwt.SigningMethodRS256, oldKey, "old", withIssuer)
+		assert.Equal(t, http.StatusOK, doAuthRequest(handler, tokenString))
+		assert.Equal(t, before, atomic.LoadInt32(&fetches))
+	})
+
+	t.Run("should reload the set when an unknown kid appears", func(t *testing.T) {
+		rotated.Store(true)
+		tokenString := signToken(t, jwt.SigningMethodRS256, newKey, "new", withIssuer)
+		assert.Equal(t, http.StatusOK, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should not look up the session of an external token", func(t *testing.T) {
+		sessions := &mockSessionChecker{revoked: map[string]bool{"idp-session": true}}
+		withSessions := middleware.NewAuthMiddleware(middleware.AuthConfig{
+			Keys:       provider,
+			Algorithms: []string{"RS256"},
+			Issuer:     "https://idp.example.com",
+			Audience:   "task-api",
+			Sessions:   sessions,
+		})
+		tokenString := signToken(t, jwt.SigningMethodRS256, oldKey, "old", func(claims *middleware.Claims) {
+			withIssuer(claims)
+			claims.SessionID = "idp-session"
+		})
+		assert.Equal(t, http.StatusOK, doAuthRequest(withSessions, tokenString))
+	})
+
+	t.Run("should reject wrong issuer", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodRS256, oldKey, "old", func(claims *middleware.Claims) {
+			withIssuer(claims)
+			claims.Issuer = "https://evil.example.com"
+		})
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should reject wrong audience", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodRS256, oldKey, "old", func(claims *middleware.Claims) {
+			withIssuer(claims)
+			claims.Audience = jwt.ClaimStrings{"another-api"}
+		})
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should use numeric subject when userId is absent", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodRS256, oldKey, "old", func(claims *middleware.Claims) {
+			withIssuer(claims)
+			claims.UserID = 0
+			claims.Subject = "77"
+		})
+		assert.Equal(t, http.StatusOK, doAuthRequest(handler, tokenString))
+	})
+
+	t.Run("should load key set from file", func(t *testing.T) {
+		data, err := json.Marshal(middleware.JWKS{Keys: []middleware.JWK{rsaJWK("old", &oldKey.PublicKey)}})
+		assert.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+		fileProvider := middleware.NewJWKSKeyProvider(path, time.Hour)
+		key, err := fileProvider.VerificationKey("old", "RS256")
+		assert.NoError(t, err)
+		assert.Equal(t, oldKey.PublicKey.N, key.(*rsa.PublicKey).N)
+	})
+}
+
+func TestTokenIssuer(t *testing.T) {
+	t.Run("should stamp issuer and audience accepted by the middleware", func(t *testing.T) {
+		issuer := middleware.TokenIssuer{Secret: "test-secret", Issuer: "task-api", Audience: "task-web"}
+		tokenString, _, err := issuer.Issue(5, "", time.Hour)
+		assert.NoError(t, err)
+
+		handler := middleware.NewAuthMiddleware(middleware.AuthConfig{
+			Keys:     middleware.NewHMACKeyProvider("test-secret"),
+			Issuer:   "task-api",
+			Audience: "task-web",
+		})
+		assert.Equal(t, http.StatusOK, doAuthRequest(handler, tokenString))
+	})
+}
+
+func TestStreamAuthMiddleware(t *testing.T) {
+	secret := "test-secret"
+	issuer := middleware.TokenIssuer{Secret: secret}
+	cfg := middleware.AuthConfig{
+		Keys:     middleware.NewHMACKeyProvider(secret),
+		Sessions: &mockSessionChecker{revoked: map[string]bool{"revoked-session": true}},
+	}
+	router := setupTestRouter()
+	router.GET("/events", middleware.NewStreamAuthMiddleware(cfg, time.Minute), func(c *gin.Context) {
+		expiresAt, _ := middleware.GetTokenExpiryFromContext(c)
+		c.JSON(200, gin.H{"sessionID": c.GetString("sessionID"), "expiresAt": expiresAt.Unix()})
+	})
+	stream := func(query, authHeader string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/events"+query, nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("should accept a stream token in the query", func(t *testing.T) {
+		tokenString, err := issuer.IssueStreamToken(1, "active-session", &expiresAt)
+		assert.NoError(t, err)
+
+		w := stream("?token="+tokenString, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "active-session")
+		assert.Contains(t, w.Body.String(), `"expiresAt":`+strconv.FormatInt(expiresAt.Unix(), 10))
+	})
+
+	t.Run("should still accept the Authorization header", func(t *testing.T) {
+		tokenString, _, err := issuer.Issue(1, "", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, stream("", "Bearer "+tokenString).Code)
+	})
+
+	t.Run("should reject access tokens in the query", func(t *testing.T) {
+		tokenString, _, err := issuer.Issue(1, "", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, stream("?token="+tokenString, "").Code)
+	})
+
+	t.Run("should reject stream tokens on other routes", func(t *testing.T) {
+		tokenString, err := issuer.IssueStreamToken(1, "", &expiresAt)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, doAuthRequest(middleware.NewAuthMiddleware(cfg), tokenString))
+	})
+
+	t.Run("should reject stream tokens used too late", func(t *testing.T) {
+		tokenString := signToken(t, jwt.SigningMethodHS256, []byte(secret), "", func(claims *middleware.Claims) {
+			claims.Scope = middleware.StreamScope
+			claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Minute))
+		})
+		w := stream("?token="+tokenString, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Stream token has expired")
+	})
+
+	t.Run("should reject stream tokens of revoked sessions", func(t *testing.T) {
+		tokenString, err := issuer.IssueStreamToken(1, "revoked-session", &expiresAt)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, stream("?token="+tokenString, "").Code)
+	})
+}
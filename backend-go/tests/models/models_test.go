@@ -183,3 +183,115 @@ func TestTaskStats(t *testing.T) {
 		assert.Equal(t, int64(10), stats.Overdue)
 	})
 }
+
+func TestUserModel(t *testing.T) {
+	t.Run("should hash and verify password", func(t *testing.T) {
+		user := &models.User{Email: "jane@example.com", Name: "Jane"}
+
+		err := user.SetPassword("correct-horse")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, user.PasswordHash)
+		assert.NotEqual(t, "correct-horse", user.PasswordHash)
+
+		assert.True(t, user.CheckPassword("correct-horse"))
+		assert.False(t, user.CheckPassword("wrong-password"))
+	})
+
+	t.Run("should normalize email", func(t *testing.T) {
+		assert.Equal(t, "jane@example.com", models.NormalizeEmail("  Jane@Example.COM "))
+	})
+}
+
+func TestRefreshTokenModel(t *testing.T) {
+	t.Run("should report expiry, use and revocation", func(t *testing.T) {
+		now := time.Now()
+		token := &models.RefreshToken{ExpiresAt: now.Add(time.Hour)}
+		assert.False(t, token.IsExpired())
+		assert.False(t, token.IsUsed())
+		assert.False(t, token.IsRevoked())
+
+		token.ExpiresAt = now.Add(-time.Minute)
+		token.UsedAt = &now
+		token.RevokedAt = &now
+		assert.True(t, token.IsExpired())
+		assert.True(t, token.IsUsed())
+		assert.True(t, token.IsRevoked())
+	})
+}
+
+func TestSubtasks(t *testing.T) {
+	t.Run("should detect subtasks", func(t *testing.T) {
+		parentID := uint(1)
+		assert.False(t, (&models.Task{}).IsSubtask())
+		assert.True(t, (&models.Task{ParentID: &parentID}).IsSubtask())
+	})
+
+	t.Run("should compute subtask progress", func(t *testing.T) {
+		task := &models.Task{
+			Subtasks: []models.Task{
+				{Status: models.StatusCompleted},
+				{Status: models.StatusPending},
+				{Status: models.StatusCompleted},
+			},
+		}
+
+		completed, total := task.SubtaskProgress()
+		assert.Equal(t, 2, completed)
+		assert.Equal(t, 3, total)
+	})
+}
+
+func TestRecurringTasks(t *testing.T) {
+	t.Run("should detect recurring tasks", func(t *testing.T) {
+		rule := "FREQ=DAILY"
+		empty := ""
+		assert.False(t, (&models.Task{}).IsRecurring())
+		assert.False(t, (&models.Task{Recurrence: &empty}).IsRecurring())
+		assert.True(t, (&models.Task{Recurrence: &rule}).IsRecurring())
+	})
+}
+
+func TestTagFilter(t *testing.T) {
+	t.Run("should split tag names", func(t *testing.T) {
+		filter := &models.TaskFilter{Tags: " frontend, ,release-1.4,"}
+		assert.Equal(t, []string{"frontend", "release-1.4"}, filter.TagNames())
+		assert.Empty(t, (&models.TaskFilter{}).TagNames())
+	})
+}
+
+func TestProjectRoles(t *testing.T) {
+	t.Run("should grant edit rights to owners and editors", func(t *testing.T) {
+		assert.True(t, models.RoleOwner.CanEdit())
+		assert.True(t, models.RoleEditor.CanEdit())
+		assert.False(t, models.RoleViewer.CanEdit())
+	})
+
+	t.Run("should only let owners manage projects", func(t *testing.T) {
+		assert.True(t, models.RoleOwner.CanManage())
+		assert.False(t, models.RoleEditor.CanManage())
+		assert.False(t, models.RoleViewer.CanManage())
+	})
+}
+
+func TestAssigneeFilter(t *testing.T) {
+	t.Run("should resolve me, none and user IDs", func(t *testing.T) {
+		id, err := (&models.TaskFilter{Assignee: models.AssigneeMe}).AssigneeUserID(7)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), *id)
+
+		id, err = (&models.TaskFilter{Assignee: models.AssigneeNone}).AssigneeUserID(7)
+		assert.NoError(t, err)
+		assert.Nil(t, id)
+
+		id, err = (&models.TaskFilter{Assignee: "42"}).AssigneeUserID(7)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(42), *id)
+	})
+
+	t.Run("should reject other values", func(t *testing.T) {
+		for _, value := range []string{"someone", "0", "-1"} {
+			_, err := (&models.TaskFilter{Assignee: value}).AssigneeUserID(7)
+			assert.Error(t, err, value)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager-backend/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one session and sends what it received on mail
+type fakeSMTPServer struct {
+	listener net.Listener
+	mail     chan string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTPServer{listener: listener, mail: make(chan string, 1)}
+	go server.serve()
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	var session strings.Builder
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		session.WriteString(line)
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "DATA"):
+			reply("354 go ahead")
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				session.WriteString(line)
+			}
+			reply("250 queued")
+		case strings.HasPrefix(command, "QUIT"):
+			reply("221 bye")
+			s.mail <- session.String()
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := notify.NewSMTPNotifier("127.0.0.1", server.port(), "", "", "Task Manager <noreply@example.com>")
+
+	err := notifier.Notify(context.Background(), &notify.Message{
+		Key:     "reminder-1-1773129600",
+		Type:    notify.TypeReminder,
+		UserID:  1,
+		Email:   "owner@example.com",
+		Subject: "\"Pay rent\"\r\nBcc: intruder@example.com is due now",
+		Body:    "Pay rent\nDue today",
+		At:      time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	var session string
+	select {
+	case session = <-server.mail:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the mail server received nothing")
+	}
+	assert.Contains(t, session, "MAIL FROM:<noreply@example.com>")
+	assert.Contains(t, session, "RCPT TO:<owner@example.com>")
+	assert.Contains(t, session, "From: Task Manager <noreply@example.com>\r\n")
+	assert.Contains(t, session, "To: owner@example.com\r\n")
+	assert.Contains(t, session, "Subject: \"Pay rent\" Bcc: intruder@example.com is due now\r\n")
+	assert.NotContains(t, session, "\r\nBcc:", "line breaks in the subject must not add headers")
+	assert.Contains(t, session, "Pay rent\r\nDue today\r\n")
+}
+
+func TestSMTPNotifierRequiresAnAddress(t *testing.T) {
+	notifier := notify.NewSMTPNotifier("127.0.0.1", 1, "", "", "noreply@example.com")
+	err := notifier.Notify(context.Background(), &notify.Message{Key: "digest-1-2026-03-10", UserID: 1})
+	assert.EqualError(t, err, "user has no email address")
+}
@@ -0,0 +1,98 @@
+package patch_test
+
+import (
+	"testing"
+
+	"task-manager-backend/internal/patch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"replaces members", `{"a":"b","c":"d"}`, `{"a":"z"}`, `{"a":"z","c":"d"}`},
+		{"removes members set to null", `{"a":"b","c":"d"}`, `{"a":null}`, `{"c":"d"}`},
+		{"merges nested objects", `{"a":{"b":"c","d":"e"}}`, `{"a":{"b":null,"f":"g"}}`, `{"a":{"d":"e","f":"g"}}`},
+		{"replaces arrays wholesale", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"replaces non-objects", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"drops nulls inside new objects", `{}`, `{"a":{"b":null}}`, `{"a":{}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.Merge([]byte(tt.doc), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("should reject malformed patches", func(t *testing.T) {
+		_, err := patch.Merge([]byte(`{}`), []byte(`{`))
+		assert.Error(t, err)
+	})
+}
+
+func TestApply(t *testing.T) {
+	doc := `{"title":"Task","tags":["a","b"],"meta":{"x/y":1,"m~n":2}}`
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"adds a member", `[{"op":"add","path":"/description","value":"text"}]`,
+			`{"title":"Task","description":"text","tags":["a","b"],"meta":{"x/y":1,"m~n":2}}`},
+		{"inserts into an array", `[{"op":"add","path":"/tags/1","value":"c"}]`,
+			`{"title":"Task","tags":["a","c","b"],"meta":{"x/y":1,"m~n":2}}`},
+		{"appends to an array", `[{"op":"add","path":"/tags/-","value":"c"}]`,
+			`{"title":"Task","tags":["a","b","c"],"meta":{"x/y":1,"m~n":2}}`},
+		{"removes an array element", `[{"op":"remove","path":"/tags/0"}]`,
+			`{"title":"Task","tags":["b"],"meta":{"x/y":1,"m~n":2}}`},
+		{"replaces a value", `[{"op":"replace","path":"/title","value":null}]`,
+			`{"title":null,"tags":["a","b"],"meta":{"x/y":1,"m~n":2}}`},
+		{"unescapes pointers", `[{"op":"remove","path":"/meta/x~1y"},{"op":"replace","path":"/meta/m~0n","value":3}]`,
+			`{"title":"Task","tags":["a","b"],"meta":{"m~n":3}}`},
+		{"moves a value", `[{"op":"move","from":"/title","path":"/name"}]`,
+			`{"name":"Task","tags":["a","b"],"meta":{"x/y":1,"m~n":2}}`},
+		{"copies a value", `[{"op":"copy","from":"/tags","path":"/labels"}]`,
+			`{"title":"Task","tags":["a","b"],"labels":["a","b"],"meta":{"x/y":1,"m~n":2}}`},
+		{"passes a matching test", `[{"op":"test","path":"/meta","value":{"m~n":2,"x/y":1.0}},{"op":"replace","path":"/title","value":"Done"}]`,
+			`{"title":"Done","tags":["a","b"],"meta":{"x/y":1,"m~n":2}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.Apply([]byte(doc), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("should report failed tests", func(t *testing.T) {
+		_, err := patch.Apply([]byte(doc), []byte(`[{"op":"test","path":"/title","value":"Other"}]`))
+		assert.ErrorIs(t, err, patch.ErrTestFailed)
+	})
+
+	t.Run("should reject invalid operations", func(t *testing.T) {
+		for _, p := range []string{
+			`[{"op":"remove","path":"/missing"}]`,
+			`[{"op":"replace","path":"/tags/5","value":"x"}]`,
+			`[{"op":"add","path":"/tags/01","value":"x"}]`,
+			`[{"op":"add","path":"title","value":"x"}]`,
+			`[{"op":"add","path":"/title"}]`,
+			`[{"op":"move","from":"/meta","path":"/meta/inner"}]`,
+			`[{"op":"increment","path":"/title"}]`,
+			`{"op":"add"}`,
+		} {
+			_, err := patch.Apply([]byte(doc), []byte(p))
+			assert.Error(t, err, p)
+			assert.NotErrorIs(t, err, patch.ErrTestFailed, p)
+		}
+	})
+}
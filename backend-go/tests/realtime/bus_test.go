@@ -0,0 +1,74 @@
+package realtime_test
+
+import (
+	"testing"
+
+	"task-manager-backend/internal/realtime"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("should deliver events to the users they are addressed to", func(t *testing.T) {
+		bus := realtime.NewBus(10, 10)
+		alice, _ := bus.Subscribe(1, nil)
+		bob, _ := bus.Subscribe(2, nil)
+
+		bus.Publish([]uint{1}, "task.created", map[string]int{"taskId": 7})
+
+		event := <-alice.Events()
+		assert.Equal(t, uint64(1), event.ID)
+		assert.Equal(t, "task.created", event.Type)
+		assert.JSONEq(t, `{"taskId":7}`, string(event.Data))
+		assert.Empty(t, bob.Events())
+	})
+
+	t.Run("should replay missed events after the last event ID", func(t *testing.T) {
+		bus := realtime.NewBus(10, 10)
+		for i := 0; i < 3; i++ {
+			bus.Publish([]uint{1}, "task.updated", i)
+		}
+		bus.Publish([]uint{2}, "task.updated", "other")
+
+		lastID := uint64(1)
+		_, replay := bus.Subscribe(1, &lastID)
+		assert.True(t, replay.Complete)
+		require.Len(t, replay.Events, 2)
+		assert.Equal(t, uint64(2), replay.Events[0].ID)
+		assert.Equal(t, uint64(4), replay.LastID)
+	})
+
+	t.Run("should report events that are no longer buffered", func(t *testing.T) {
+		bus := realtime.NewBus(2, 10)
+		for i := 0; i < 5; i++ {
+			bus.Publish([]uint{1}, "task.updated", i)
+		}
+
+		lastID := uint64(1)
+		_, replay := bus.Subscribe(1, &lastID)
+		assert.False(t, replay.Complete)
+		assert.Len(t, replay.Events, 2)
+
+		// An ID from before a restart is ahead of the bus
+		lastID = 99
+		_, replay = bus.Subscribe(1, &lastID)
+		assert.False(t, replay.Complete)
+		assert.Empty(t, replay.Events)
+	})
+
+	t.Run("should drop subscribers that fall behind", func(t *testing.T) {
+		bus := realtime.NewBus(10, 1)
+		slow, _ := bus.Subscribe(1, nil)
+
+		bus.Publish([]uint{1}, "task.updated", 1)
+		bus.Publish([]uint{1}, "task.updated", 2)
+
+		select {
+		case <-slow.Dropped():
+		default:
+			t.Fatal("expected the subscriber to be dropped")
+		}
+		assert.Len(t, slow.Events(), 1)
+	})
+}
@@ -0,0 +1,135 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+
+	"task-manager-backend/internal/recurrence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+func times(occurrences []recurrence.Occurrence) []time.Time {
+	result := make([]time.Time, 0, len(occurrences))
+	for _, o := range occurrences {
+		result = append(result, o.Time)
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	t.Run("should parse and canonicalize a rule", func(t *testing.T) {
+		rule, err := recurrence.Parse("RRULE:freq=weekly;byday=MO,WE;interval=2;count=10")
+		require.NoError(t, err)
+
+		assert.Equal(t, recurrence.Weekly, rule.Freq)
+		assert.Equal(t, 2, rule.Interval)
+		assert.Equal(t, 10, rule.Count)
+		assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10", rule.String())
+	})
+
+	t.Run("should reject invalid rules", func(t *testing.T) {
+		invalid := []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=YEARLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;COUNT=3;UNTIL=20240101",
+			"FREQ=WEEKLY;BYDAY=1MO",
+			"FREQ=MONTHLY;BYDAY=XX",
+			"FREQ=DAILY;FREQ=WEEKLY",
+			"FREQ=DAILY;BYMONTH=1",
+			"FREQ=DAILY;UNTIL=tomorrow",
+		}
+		for _, value := range invalid {
+			_, err := recurrence.Parse(value)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+func TestOccurrences(t *testing.T) {
+	t.Run("should expand daily rules with an interval", func(t *testing.T) {
+		rule, err := recurrence.Parse("FREQ=DAILY;INTERVAL=3")
+		require.NoError(t, err)
+
+		start := date(2024, time.March, 1)
+		occurrences := rule.After(start, start, 2)
+		assert.Equal(t, []time.Time{date(2024, time.March, 4), date(2024, time.March, 7)}, times(occurrences))
+		assert.Equal(t, 2, occurrences[0].Index)
+	})
+
+	t.Run("should expand weekly rules on selected weekdays", func(t *testing.T) {
+		rule, err := recurrence.Parse("FREQ=WEEKLY;BYDAY=MO,FR")
+		require.NoError(t, err)
+
+		// 2024-03-06 is a Wednesday
+		start := date(2024, time.March, 6)
+		occurrences := rule.After(start, start, 3)
+		assert.Equal(t, []time.Time{
+			date(2024, time.March, 8),
+			date(2024, time.March, 11),
+			date(2024, time.March, 15),
+		}, times(occurrences))
+	})
+
+	t.Run("should skip months without the start day", func(t *testing.T) {
+		rule, err := recurrence.Parse("FREQ=MONTHLY")
+		require.NoError(t, err)
+
+		start := date(2024, time.January, 31)
+		occurrences := rule.After(start, start, 3)
+		assert.Equal(t, []time.Time{
+			date(2024, time.March, 31),
+			date(2024, time.May, 31),
+			date(2024, time.July, 31),
+		}, times(occurrences))
+	})
+
+	t.Run("should support ordinal weekdays in monthly rules", func(t *testing.T) {
+		rule, err := recurrence.Parse("FREQ=MONTHLY;BYDAY=-1FR")
+		require.NoError(t, err)
+
+		start := date(2024, time.January, 26)
+		occurrences := rule.After(start, start, 2)
+		assert.Equal(t, []time.Time{date(2024, time.February, 23), date(2024, time.March, 29)}, times(occurrences))
+	})
+
+	t.Run("should stop after COUNT occurrences", func(t *testing.T) {
+		rule, err := recurrence.Parse("FREQ=DAILY;COUNT=3")
+		require.NoError(t, err)
+
+		start := date(2024, time.March, 1)
+		occurrences := rule.After(start, start, 10)
+		assert.Len(t, occurrences, 2)
+		assert.Equal(t, 3, occurrences[1].Index)
+
+		_, ok := rule.Next(start, date(2024, time.March, 3))
+		assert.False(t, ok)
+	})
+
+	t.Run("should stop at UNTIL", func(t *testing.T) {
+		rule, err := recurrence.Parse("FREQ=WEEKLY;UNTIL=20240315")
+		require.NoError(t, err)
+
+		start := date(2024, time.March, 1)
+		occurrences := rule.After(start, start, 10)
+		assert.Equal(t, []time.Time{date(2024, time.March, 8), date(2024, time.March, 15)}, times(occurrences))
+	})
+
+	t.Run("should continue from a later due date", func(t *testing.T) {
+		rule, err := recurrence.Parse("FREQ=WEEKLY")
+		require.NoError(t, err)
+
+		start := date(2024, time.March, 1)
+		next, ok := rule.Next(start, date(2024, time.March, 15))
+		require.True(t, ok)
+		assert.Equal(t, date(2024, time.March, 22), next.Time)
+		assert.Equal(t, 4, next.Index)
+	})
+}
@@ -0,0 +1,55 @@
+package search_test
+
+import (
+	"strings"
+	"testing"
+
+	"task-manager-backend/internal/search"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	t.Run("should split queries into lower-case words", func(t *testing.T) {
+		assert.Equal(t, []string{"renew", "passport"}, search.Terms(`"Renew" passport`))
+	})
+
+	t.Run("should drop operators, wildcards and duplicates", func(t *testing.T) {
+		assert.Equal(t, []string{"tax", "100", "return"}, search.Terms("tax or 100% -tax return_"))
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	t.Run("should mark every matching term", func(t *testing.T) {
+		snippet := search.Highlight("Renew Passport before the trip", []string{"passport", "trip"})
+		assert.Equal(t, "Renew <mark>Passport</mark> before the <mark>trip</mark>", snippet)
+	})
+
+	t.Run("should trim long text around the first match", func(t *testing.T) {
+		text := "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor " +
+			"incididunt ut labore et dolore magna aliqua. Remember the passport. Ut enim ad minim " +
+			"veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat."
+		snippet := search.Highlight(text, []string{"passport"})
+
+		assert.Contains(t, snippet, "<mark>passport</mark>")
+		assert.True(t, len(snippet) < len(text))
+		assert.True(t, strings.HasPrefix(snippet, "..."))
+		assert.True(t, strings.HasSuffix(snippet, "..."))
+	})
+
+	t.Run("should escape the task text", func(t *testing.T) {
+		snippet := search.Highlight(`<script>alert("x")</script> & passport`, []string{"passport", "script"})
+		assert.Equal(t, `&lt;<mark>script</mark>&gt;alert(&#34;x&#34;)&lt;/<mark>script</mark>&gt; &amp; <mark>passport</mark>`, snippet)
+	})
+
+	t.Run("should return nothing without a match", func(t *testing.T) {
+		assert.Equal(t, "", search.Highlight("Buy groceries", []string{"passport"}))
+	})
+}
+
+func TestFormatHeadline(t *testing.T) {
+	t.Run("should escape ts_headline output and mark its matches", func(t *testing.T) {
+		snippet := search.FormatHeadline("<script>alert(1)</script> renew \ue000passport\ue001")
+		assert.Equal(t, "&lt;script&gt;alert(1)&lt;/script&gt; renew <mark>passport</mark>", snippet)
+	})
+}
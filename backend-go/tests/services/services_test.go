@@ -1,15 +1,29 @@
 package services_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	_ "time/tzdata"
 
+	"task-manager-backend/internal/database"
 	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/notify"
 	"task-manager-backend/internal/services"
 
+	"github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // MockDB is a mock implementation of gorm.DB for testing
@@ -289,3 +303,2211 @@ func TestHelperFunctions(t *testing.T) {
 		assert.True(t, *b)
 	})
 }
+
+// newTestDB opens a migrated in-memory SQLite database
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: is a separate database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, database.Migrate(db))
+	return db
+}
+
+func TestUserAccounts(t *testing.T) {
+	db := newTestDB(t)
+	userService := services.NewUserService(db)
+
+	user, err := userService.Register(&models.RegisterRequest{Email: "Jane@Example.com", Name: "Jane", Password: "password123"})
+	require.NoError(t, err)
+
+	t.Run("should reject a registered email", func(t *testing.T) {
+		_, err := userService.Register(&models.RegisterRequest{Email: "jane@example.com", Name: "Jane", Password: "password123"})
+		assert.EqualError(t, err, "email already registered")
+	})
+
+	t.Run("should report a unique violation as a registered email", func(t *testing.T) {
+		// A deleted account passes the check but still holds the email in the unique index,
+		// like a concurrent signup that inserted first
+		require.NoError(t, db.Delete(user).Error)
+		_, err := userService.Register(&models.RegisterRequest{Email: "jane@example.com", Name: "Jane", Password: "password123"})
+		assert.EqualError(t, err, "email already registered")
+	})
+
+	t.Run("should reject unknown emails like wrong passwords", func(t *testing.T) {
+		_, err := userService.Login(&models.LoginRequest{Email: "nobody@example.com", Password: "password123"})
+		assert.EqualError(t, err, "invalid credentials")
+	})
+}
+
+func TestTaskSearch(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+
+	create := func(userID uint, title string, description *string) *models.Task {
+		task, err := taskService.CreateTask(userID, &models.CreateTaskRequest{Title: title, Description: description})
+		require.NoError(t, err)
+		return task
+	}
+
+	titleMatch := create(1, "Renew passport", stringPtr("Book an appointment at the office"))
+	descriptionMatch := create(1, "Travel checklist", stringPtr("Pack bags and bring the passport"))
+	create(1, "Buy groceries", nil)
+	create(2, "Passport for someone else", nil)
+
+	search := func(q string) []models.Task {
+		tasks, _, err := taskService.GetTasksByUser(1, &models.TaskFilter{Q: q, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		return tasks
+	}
+
+	t.Run("should rank title matches above description matches", func(t *testing.T) {
+		tasks := search("passport")
+		require.Len(t, tasks, 2)
+		assert.Equal(t, titleMatch.ID, tasks[0].ID)
+		assert.Equal(t, descriptionMatch.ID, tasks[1].ID)
+		require.NotNil(t, tasks[0].SearchRank)
+		require.NotNil(t, tasks[1].SearchRank)
+		assert.Greater(t, *tasks[0].SearchRank, *tasks[1].SearchRank)
+	})
+
+	t.Run("should highlight matches in snippets", func(t *testing.T) {
+		tasks := search("PASSPORT")
+		require.Len(t, tasks, 2)
+		require.NotNil(t, tasks[1].Snippet)
+		assert.Contains(t, *tasks[1].Snippet, "<mark>passport</mark>")
+	})
+
+	t.Run("should leave out rank and snippets when sorted", func(t *testing.T) {
+		tasks, _, err := taskService.GetTasksByUser(1, &models.TaskFilter{Q: "passport", Sort: "title", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, titleMatch.ID, tasks[0].ID)
+		assert.Nil(t, tasks[0].SearchRank)
+		assert.Nil(t, tasks[0].Snippet)
+		assert.Nil(t, tasks[1].Snippet)
+	})
+
+	t.Run("should require every term to match", func(t *testing.T) {
+		tasks := search("passport office")
+		require.Len(t, tasks, 1)
+		assert.Equal(t, titleMatch.ID, tasks[0].ID)
+	})
+
+	t.Run("should count only matching tasks", func(t *testing.T) {
+		_, total, err := taskService.GetTasksByUser(1, &models.TaskFilter{Q: "groceries", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("should not search without a query", func(t *testing.T) {
+		tasks := search("")
+		assert.Len(t, tasks, 3)
+		assert.Nil(t, tasks[0].SearchRank)
+		assert.Nil(t, tasks[0].Snippet)
+	})
+
+	t.Run("should escape markup in snippets", func(t *testing.T) {
+		create(3, `<script>alert("passport")</script>`, nil)
+		tasks, _, err := taskService.GetTasksByUser(3, &models.TaskFilter{Q: "passport", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		require.NotNil(t, tasks[0].Snippet)
+		assert.Equal(t, `&lt;script&gt;alert(&#34;<mark>passport</mark>&#34;)&lt;/script&gt;`, *tasks[0].Snippet)
+	})
+}
+
+func TestTags(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	tagService := services.NewTagService(db, taskService)
+
+	create := func(title string, tags ...string) *models.Task {
+		task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: title, Tags: tags})
+		require.NoError(t, err)
+		return task
+	}
+	list := func(tags, mode string) []uint {
+		tasks, _, err := taskService.GetTasksByUser(1, &models.TaskFilter{Tags: tags, TagMode: mode, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		ids := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	both := create("Fix login page", "frontend", "release-1.4", "frontend")
+	frontend := create("Polish buttons", "frontend")
+	release := create("Write changelog", "release-1.4")
+	create("Untagged")
+
+	t.Run("should create missing tags once", func(t *testing.T) {
+		require.Len(t, both.Tags, 2)
+		assert.Equal(t, "frontend", both.Tags[0].Name)
+		assert.Equal(t, models.DefaultTagColor, both.Tags[0].Color)
+
+		tags, err := tagService.GetTags(1)
+		require.NoError(t, err)
+		assert.Len(t, tags, 2)
+	})
+
+	t.Run("should filter by any tag", func(t *testing.T) {
+		assert.ElementsMatch(t, []uint{both.ID, frontend.ID, release.ID}, list("frontend, release-1.4", ""))
+	})
+
+	t.Run("should filter by all tags", func(t *testing.T) {
+		assert.Equal(t, []uint{both.ID}, list("frontend,release-1.4", models.TagModeAll))
+	})
+
+	t.Run("should treat tag names that differ in case as one tag", func(t *testing.T) {
+		shouting := create("Review copy", " FrontEnd ")
+		require.Len(t, shouting.Tags, 1)
+		assert.Equal(t, "frontend", shouting.Tags[0].Name)
+		tags, err := tagService.GetTags(1)
+		require.NoError(t, err)
+		assert.Len(t, tags, 2)
+
+		_, err = tagService.CreateTag(1, &models.CreateTagRequest{Name: "Release-1.4"})
+		assert.EqualError(t, err, "tag already exists")
+
+		assert.ElementsMatch(t, []uint{both.ID, frontend.ID, shouting.ID}, list("FRONTEND", ""))
+		assert.Equal(t, []uint{both.ID}, list("Frontend,RELEASE-1.4", models.TagModeAll))
+		require.NoError(t, taskService.DeleteTask(1, shouting.ID))
+	})
+
+	t.Run("should match tags named in mixed case before", func(t *testing.T) {
+		legacy := models.Tag{Name: "Urgent", Color: models.DefaultTagColor, UserID: 1}
+		require.NoError(t, db.Create(&legacy).Error)
+
+		urgent := create("Call back", "urgent")
+		require.Len(t, urgent.Tags, 1)
+		assert.Equal(t, legacy.ID, urgent.Tags[0].ID)
+		assert.Equal(t, []uint{urgent.ID}, list("urgent", ""))
+		require.NoError(t, taskService.DeleteTask(1, urgent.ID))
+		require.NoError(t, tagService.DeleteTag(1, legacy.ID))
+	})
+
+	t.Run("should break stats down per tag", func(t *testing.T) {
+		_, err := taskService.MarkTaskAsCompleted(1, release.ID)
+		require.NoError(t, err)
+
+		stats, err := taskService.GetTaskStats(1)
+		require.NoError(t, err)
+		require.Len(t, stats.ByTag, 2)
+		assert.Equal(t, "frontend", stats.ByTag[0].Name)
+		assert.Equal(t, int64(2), stats.ByTag[0].Total)
+		assert.Equal(t, int64(2), stats.ByTag[0].Pending)
+		assert.Equal(t, "release-1.4", stats.ByTag[1].Name)
+		assert.Equal(t, int64(1), stats.ByTag[1].Pending)
+		assert.Equal(t, int64(1), stats.ByTag[1].Completed)
+	})
+
+	t.Run("should replace tags on update", func(t *testing.T) {
+		tags := []string{"customer-x"}
+		task, err := taskService.UpdateTask(1, frontend.ID, &models.UpdateTaskRequest{Tags: &tags})
+		require.NoError(t, err)
+		require.Len(t, task.Tags, 1)
+		assert.Equal(t, "customer-x", task.Tags[0].Name)
+		assert.ElementsMatch(t, []uint{both.ID}, list("frontend", ""))
+	})
+
+	t.Run("should keep tags when an update omits them", func(t *testing.T) {
+		task, err := taskService.UpdateTask(1, both.ID, &models.UpdateTaskRequest{Title: stringPtr("Fix login form")})
+		require.NoError(t, err)
+		assert.Len(t, task.Tags, 2)
+	})
+
+	t.Run("should rename and recolor tags", func(t *testing.T) {
+		tag, err := tagService.CreateTag(1, &models.CreateTagRequest{Name: "backend"})
+		require.NoError(t, err)
+
+		_, err = tagService.UpdateTag(1, tag.ID, &models.UpdateTagRequest{Name: stringPtr("frontend")})
+		assert.EqualError(t, err, "tag already exists")
+
+		updated, err := tagService.UpdateTag(1, tag.ID, &models.UpdateTagRequest{Name: stringPtr("api"), Color: stringPtr("#ff0000")})
+		require.NoError(t, err)
+		assert.Equal(t, "api", updated.Name)
+		assert.Equal(t, "#ff0000", updated.Color)
+
+		_, err = tagService.UpdateTag(2, tag.ID, &models.UpdateTagRequest{Name: stringPtr("other")})
+		assert.EqualError(t, err, "tag not found")
+	})
+
+	t.Run("should detach deleted tags from tasks", func(t *testing.T) {
+		tags, err := tagService.GetTags(1)
+		require.NoError(t, err)
+		for _, tag := range tags {
+			if tag.Name == "release-1.4" {
+				require.NoError(t, tagService.DeleteTag(1, tag.ID))
+			}
+		}
+
+		task, err := taskService.GetTaskWithSubtasks(1, both.ID)
+		require.NoError(t, err)
+		require.Len(t, task.Tags, 1)
+		assert.Equal(t, "frontend", task.Tags[0].Name)
+		assert.Empty(t, list("release-1.4", ""))
+	})
+}
+
+func TestProjects(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	projectService := services.NewProjectService(db, taskService)
+	userService := services.NewUserService(db)
+
+	register := func(email string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: email, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	owner := register("owner@example.com")
+	editor := register("editor@example.com")
+	viewer := register("viewer@example.com")
+	outsider := register("outsider@example.com")
+
+	project, err := projectService.CreateProject(owner, &models.CreateProjectRequest{Name: "Release 1.4"})
+	require.NoError(t, err)
+	_, err = projectService.AddMember(owner, project.ID, &models.AddMemberRequest{Email: "editor@example.com", Role: models.RoleEditor})
+	require.NoError(t, err)
+	_, err = projectService.AddMember(owner, project.ID, &models.AddMemberRequest{Email: "viewer@example.com", Role: models.RoleViewer})
+	require.NoError(t, err)
+
+	shared, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Shared task", ProjectID: &project.ID})
+	require.NoError(t, err)
+	private, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Private task"})
+	require.NoError(t, err)
+
+	t.Run("should share project tasks with members only", func(t *testing.T) {
+		tasks, total, err := taskService.GetTasksByUser(editor, &models.TaskFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, shared.ID, tasks[0].ID)
+
+		_, err = taskService.GetTaskByID(editor, private.ID)
+		assert.EqualError(t, err, "task not found")
+		_, err = taskService.GetTaskByID(outsider, shared.ID)
+		assert.EqualError(t, err, "task not found")
+	})
+
+	t.Run("should filter by project", func(t *testing.T) {
+		privateOnly := uint(0)
+		tasks, _, err := taskService.GetTasksByUser(owner, &models.TaskFilter{ProjectID: &privateOnly, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, private.ID, tasks[0].ID)
+
+		tasks, _, err = taskService.GetTasksByUser(owner, &models.TaskFilter{ProjectID: &project.ID, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, shared.ID, tasks[0].ID)
+	})
+
+	t.Run("should let editors change shared tasks", func(t *testing.T) {
+		task, err := taskService.UpdateTask(editor, shared.ID, &models.UpdateTaskRequest{Title: stringPtr("Edited by teammate")})
+		require.NoError(t, err)
+		assert.Equal(t, "Edited by teammate", task.Title)
+
+		subtask, err := taskService.CreateSubtask(editor, shared.ID, &models.CreateTaskRequest{Title: "Step"})
+		require.NoError(t, err)
+		require.NotNil(t, subtask.ProjectID)
+		assert.Equal(t, project.ID, *subtask.ProjectID)
+	})
+
+	t.Run("should keep viewers read-only", func(t *testing.T) {
+		_, err := taskService.GetTaskByID(viewer, shared.ID)
+		require.NoError(t, err)
+
+		_, err = taskService.MarkTaskAsCompleted(viewer, shared.ID)
+		assert.EqualError(t, err, "insufficient permissions")
+		err = taskService.DeleteTask(viewer, shared.ID)
+		assert.EqualError(t, err, "insufficient permissions")
+		_, err = taskService.CreateTask(viewer, &models.CreateTaskRequest{Title: "Nope", ProjectID: &project.ID})
+		assert.EqualError(t, err, "insufficient permissions")
+		_, err = taskService.CreateTask(outsider, &models.CreateTaskRequest{Title: "Nope", ProjectID: &project.ID})
+		assert.EqualError(t, err, "project not found")
+	})
+
+	t.Run("should count shared tasks in stats", func(t *testing.T) {
+		stats, err := taskService.GetTaskStats(viewer)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stats.Total)
+		assert.Equal(t, int64(1), stats.Subtasks)
+	})
+
+	t.Run("should only let the creator make a task private", func(t *testing.T) {
+		privateOnly := uint(0)
+		_, err := taskService.UpdateTask(editor, shared.ID, &models.UpdateTaskRequest{ProjectID: &privateOnly})
+		assert.EqualError(t, err, "insufficient permissions")
+
+		moved, err := taskService.CreateTask(editor, &models.CreateTaskRequest{Title: "Draft"})
+		require.NoError(t, err)
+		moved, err = taskService.UpdateTask(editor, moved.ID, &models.UpdateTaskRequest{ProjectID: &project.ID})
+		require.NoError(t, err)
+		require.NotNil(t, moved.ProjectID)
+		_, err = taskService.GetTaskByID(viewer, moved.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should manage members", func(t *testing.T) {
+		_, err := projectService.AddMember(editor, project.ID, &models.AddMemberRequest{Email: "outsider@example.com", Role: models.RoleViewer})
+		assert.EqualError(t, err, "insufficient permissions")
+		_, err = projectService.AddMember(owner, project.ID, &models.AddMemberRequest{Email: "editor@example.com", Role: models.RoleViewer})
+		assert.EqualError(t, err, "user is already a member")
+
+		err = projectService.RemoveMember(owner, project.ID, owner)
+		assert.EqualError(t, err, "project must keep an owner")
+		_, err = projectService.UpdateMemberRole(owner, project.ID, owner, &models.UpdateMemberRequest{Role: models.RoleEditor})
+		assert.EqualError(t, err, "project must keep an owner")
+
+		require.NoError(t, projectService.RemoveMember(viewer, project.ID, viewer))
+		_, err = taskService.GetTaskByID(viewer, shared.ID)
+		assert.EqualError(t, err, "task not found")
+
+		loaded, err := projectService.GetProject(editor, project.ID)
+		require.NoError(t, err)
+		require.Len(t, loaded.Members, 2)
+		assert.Equal(t, "owner@example.com", loaded.Members[0].User.Email)
+	})
+
+	t.Run("should delete projects with their tasks", func(t *testing.T) {
+		err := projectService.DeleteProject(editor, project.ID)
+		assert.EqualError(t, err, "insufficient permissions")
+
+		require.NoError(t, projectService.DeleteProject(owner, project.ID))
+		_, err = taskService.GetTaskByID(owner, shared.ID)
+		assert.EqualError(t, err, "task not found")
+		_, err = projectService.GetProject(owner, project.ID)
+		assert.EqualError(t, err, "project not found")
+
+		projects, err := projectService.GetProjects(owner)
+		require.NoError(t, err)
+		assert.Empty(t, projects)
+	})
+}
+
+func TestAssignment(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	projectService := services.NewProjectService(db, taskService)
+	userService := services.NewUserService(db)
+
+	register := func(email string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: email, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	lead := register("lead@example.com")
+	dev := register("dev@example.com")
+	outsider := register("outsider@example.com")
+
+	project, err := projectService.CreateProject(lead, &models.CreateProjectRequest{Name: "Backlog"})
+	require.NoError(t, err)
+	_, err = projectService.AddMember(lead, project.ID, &models.AddMemberRequest{Email: "dev@example.com", Role: models.RoleEditor})
+	require.NoError(t, err)
+
+	shared, err := taskService.CreateTask(lead, &models.CreateTaskRequest{Title: "Fix bug", ProjectID: &project.ID})
+	require.NoError(t, err)
+	other, err := taskService.CreateTask(lead, &models.CreateTaskRequest{Title: "Write docs", ProjectID: &project.ID})
+	require.NoError(t, err)
+	private, err := taskService.CreateTask(lead, &models.CreateTaskRequest{Title: "Private"})
+	require.NoError(t, err)
+
+	list := func(userID uint, assignee string) []uint {
+		tasks, _, err := taskService.GetTasksByUser(userID, &models.TaskFilter{Assignee: assignee, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		ids := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	t.Run("should record who assigned a task and when", func(t *testing.T) {
+		task, err := taskService.AssignTask(lead, shared.ID, dev)
+		require.NoError(t, err)
+		assert.Equal(t, dev, *task.AssigneeID)
+		assert.Equal(t, lead, *task.AssignedByID)
+		require.NotNil(t, task.AssignedAt)
+
+		reassigned, err := taskService.AssignTask(dev, shared.ID, dev)
+		require.NoError(t, err)
+		assert.Equal(t, dev, *reassigned.AssignedByID)
+		assert.False(t, reassigned.AssignedAt.Before(*task.AssignedAt))
+	})
+
+	t.Run("should only assign users who can see the task", func(t *testing.T) {
+		_, err := taskService.AssignTask(lead, shared.ID, outsider)
+		assert.EqualError(t, err, "assignee cannot access task")
+		_, err = taskService.AssignTask(lead, private.ID, dev)
+		assert.EqualError(t, err, "assignee cannot access task")
+	})
+
+	t.Run("should filter by assignee", func(t *testing.T) {
+		assert.Equal(t, []uint{shared.ID}, list(dev, models.AssigneeMe))
+		assert.Equal(t, []uint{shared.ID}, list(lead, "2"))
+		assert.ElementsMatch(t, []uint{other.ID, private.ID}, list(lead, models.AssigneeNone))
+	})
+
+	t.Run("should split stats into created and assigned", func(t *testing.T) {
+		stats, err := taskService.GetTaskStats(dev)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stats.Total)
+		assert.Equal(t, int64(0), stats.CreatedByMe.Total)
+		assert.Equal(t, int64(1), stats.AssignedToMe.Total)
+		assert.Equal(t, int64(1), stats.AssignedToMe.Pending)
+
+		stats, err = taskService.GetTaskStats(lead)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), stats.CreatedByMe.Total)
+		assert.Equal(t, int64(0), stats.AssignedToMe.Total)
+	})
+
+	t.Run("should unassign tasks", func(t *testing.T) {
+		task, err := taskService.UnassignTask(lead, shared.ID)
+		require.NoError(t, err)
+		assert.Nil(t, task.AssigneeID)
+		assert.Nil(t, task.AssignedByID)
+		assert.Empty(t, list(dev, models.AssigneeMe))
+	})
+
+	t.Run("should unassign members who leave the project", func(t *testing.T) {
+		_, err := taskService.AssignTask(lead, other.ID, dev)
+		require.NoError(t, err)
+		require.NoError(t, projectService.RemoveMember(dev, project.ID, dev))
+
+		task, err := taskService.GetTaskByID(lead, other.ID)
+		require.NoError(t, err)
+		assert.Nil(t, task.AssigneeID)
+	})
+}
+
+func TestComments(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	projectService := services.NewProjectService(db, taskService)
+	commentService := services.NewCommentService(db, taskService)
+	userService := services.NewUserService(db)
+
+	register := func(email string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: email, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	owner := register("owner@example.com")
+	viewer := register("viewer@example.com")
+	outsider := register("outsider@example.com")
+
+	project, err := projectService.CreateProject(owner, &models.CreateProjectRequest{Name: "Launch"})
+	require.NoError(t, err)
+	_, err = projectService.AddMember(owner, project.ID, &models.AddMemberRequest{Email: "viewer@example.com", Role: models.RoleViewer})
+	require.NoError(t, err)
+
+	task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Ship it", ProjectID: &project.ID})
+	require.NoError(t, err)
+
+	comment := func(userID uint, body string) *models.Comment {
+		c, err := commentService.CreateComment(userID, task.ID, &models.CommentRequest{Body: body})
+		require.NoError(t, err)
+		return c
+	}
+	commentCount := func() int {
+		found, err := taskService.GetTaskByID(owner, task.ID)
+		require.NoError(t, err)
+		return found.CommentCount
+	}
+
+	t.Run("should let anyone who can see the task comment", func(t *testing.T) {
+		first := comment(owner, "First")
+		require.NotNil(t, first.Author)
+		assert.Equal(t, "owner@example.com", first.Author.Email)
+		comment(viewer, "Second")
+		comment(owner, "Third")
+		assert.Equal(t, 3, commentCount())
+
+		_, err := commentService.CreateComment(outsider, task.ID, &models.CommentRequest{Body: "Hi"})
+		assert.EqualError(t, err, "task not found")
+	})
+
+	t.Run("should page through comments oldest first", func(t *testing.T) {
+		page, err := commentService.GetComments(viewer, task.ID, &models.CommentFilter{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Comments, 2)
+		assert.Equal(t, "First", page.Comments[0].Body)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = commentService.GetComments(viewer, task.ID, &models.CommentFilter{Cursor: page.NextCursor, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Comments, 1)
+		assert.Equal(t, "Third", page.Comments[0].Body)
+		assert.Empty(t, page.NextCursor)
+
+		_, err = commentService.GetComments(viewer, task.ID, &models.CommentFilter{Cursor: "!", Limit: 2})
+		assert.EqualError(t, err, "invalid cursor")
+	})
+
+	t.Run("should only let authors change their comments", func(t *testing.T) {
+		own := comment(viewer, "Typo")
+		updated, err := commentService.UpdateComment(viewer, task.ID, own.ID, &models.CommentRequest{Body: "Fixed"})
+		require.NoError(t, err)
+		assert.Equal(t, "Fixed", updated.Body)
+
+		_, err = commentService.UpdateComment(owner, task.ID, own.ID, &models.CommentRequest{Body: "Nope"})
+		assert.EqualError(t, err, "insufficient permissions")
+		assert.EqualError(t, commentService.DeleteComment(owner, task.ID, own.ID), "insufficient permissions")
+
+		require.NoError(t, commentService.DeleteComment(viewer, task.ID, own.ID))
+		assert.Equal(t, 3, commentCount())
+		_, err = commentService.UpdateComment(viewer, task.ID, own.ID, &models.CommentRequest{Body: "Again"})
+		assert.EqualError(t, err, "comment not found")
+	})
+
+	t.Run("should keep the comment count when the task is saved", func(t *testing.T) {
+		title := "Ship it now"
+		updated, err := taskService.UpdateTask(owner, task.ID, &models.UpdateTaskRequest{Title: &title})
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated.CommentCount)
+		assert.Equal(t, 3, commentCount())
+	})
+
+	t.Run("should remove comments with their task", func(t *testing.T) {
+		require.NoError(t, taskService.DeleteTask(owner, task.ID))
+
+		var remaining int64
+		require.NoError(t, db.Model(&models.Comment{}).Where("task_id = ?", task.ID).Count(&remaining).Error)
+		assert.Zero(t, remaining)
+	})
+}
+
+func TestTaskEvents(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	projectService := services.NewProjectService(db, taskService)
+	eventService := services.NewEventService(db)
+	userService := services.NewUserService(db)
+
+	register := func(email string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: email, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	owner := register("owner@example.com")
+	editor := register("editor@example.com")
+	outsider := register("outsider@example.com")
+
+	project, err := projectService.CreateProject(owner, &models.CreateProjectRequest{Name: "Audit"})
+	require.NoError(t, err)
+	_, err = projectService.AddMember(owner, project.ID, &models.AddMemberRequest{Email: "editor@example.com", Role: models.RoleEditor})
+	require.NoError(t, err)
+
+	task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Draft", ProjectID: &project.ID, Tags: []string{"docs"}})
+	require.NoError(t, err)
+
+	history := func() []models.TaskEvent {
+		events, err := eventService.GetTaskHistory(owner, task.ID)
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("should record creation with the initial values", func(t *testing.T) {
+		events := history()
+		require.Len(t, events, 1)
+		assert.Equal(t, models.OperationCreate, events[0].Operation)
+		assert.Equal(t, owner, events[0].ActorID)
+		require.NotNil(t, events[0].Actor)
+		assert.Equal(t, models.FieldChange{Before: nil, After: "Draft"}, events[0].Changes["title"])
+		assert.Equal(t, []interface{}{"docs"}, events[0].Changes["tags"].After)
+		assert.NotContains(t, events[0].Changes, "description")
+	})
+
+	t.Run("should record only the fields an update changed", func(t *testing.T) {
+		title := "Final"
+		priority := models.PriorityHigh
+		_, err := taskService.UpdateTask(editor, task.ID, &models.UpdateTaskRequest{Title: &title, Priority: &priority, Tags: &[]string{"docs", "release"}})
+		require.NoError(t, err)
+
+		events := history()
+		require.Len(t, events, 2)
+		update := events[1]
+		assert.Equal(t, models.OperationUpdate, update.Operation)
+		assert.Equal(t, editor, update.ActorID)
+		assert.Len(t, update.Changes, 3)
+		assert.Equal(t, models.FieldChange{Before: "Draft", After: "Final"}, update.Changes["title"])
+		assert.Equal(t, models.FieldChange{Before: "medium", After: "high"}, update.Changes["priority"])
+		assert.Equal(t, []interface{}{"docs", "release"}, update.Changes["tags"].After)
+
+		_, err = taskService.UpdateTask(editor, task.ID, &models.UpdateTaskRequest{Title: &title})
+		require.NoError(t, err)
+		assert.Len(t, history(), 2)
+	})
+
+	t.Run("should record status changes and assignment", func(t *testing.T) {
+		_, err := taskService.MarkTaskAsCompleted(owner, task.ID)
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsPending(owner, task.ID)
+		require.NoError(t, err)
+		_, err = taskService.AssignTask(owner, task.ID, editor)
+		require.NoError(t, err)
+
+		events := history()
+		require.Len(t, events, 5)
+		assert.Equal(t, models.OperationComplete, events[2].Operation)
+		assert.Equal(t, models.FieldChange{Before: "pending", After: "completed"}, events[2].Changes["status"])
+		assert.Equal(t, models.OperationReopen, events[3].Operation)
+		assert.Equal(t, models.OperationAssign, events[4].Operation)
+		assert.Equal(t, float64(editor), events[4].Changes["assigneeId"].After)
+	})
+
+	t.Run("should not allow events to be changed", func(t *testing.T) {
+		event := history()[0]
+		assert.Error(t, db.Model(&event).Update("operation", "delete").Error)
+		assert.Error(t, db.Delete(&event).Error)
+	})
+
+	t.Run("should hide history from users who cannot see the task", func(t *testing.T) {
+		_, err := eventService.GetTaskHistory(outsider, task.ID)
+		assert.EqualError(t, err, "task not found")
+	})
+
+	t.Run("should keep deleted tasks in the activity feed", func(t *testing.T) {
+		require.NoError(t, taskService.DeleteTask(editor, task.ID))
+
+		events, total, err := eventService.GetActivity(owner, &models.ActivityFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), total)
+		assert.Equal(t, models.OperationDelete, events[0].Operation)
+		assert.Equal(t, "Final", events[0].Changes["title"].Before)
+
+		events, total, err = eventService.GetActivity(outsider, &models.ActivityFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, events)
+	})
+
+	t.Run("should filter the activity feed by time range", func(t *testing.T) {
+		future := time.Now().Add(time.Hour)
+		_, total, err := eventService.GetActivity(owner, &models.ActivityFilter{From: &future, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+
+		past := time.Now().Add(-time.Hour)
+		events, total, err := eventService.GetActivity(owner, &models.ActivityFilter{From: &past, To: &future, Page: 1, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), total)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("should record changes made alongside other writes", func(t *testing.T) {
+		commentService := services.NewCommentService(db, taskService)
+		tagService := services.NewTagService(db, taskService)
+		events := func(taskID uint) []models.TaskEvent {
+			events, err := eventService.GetTaskHistory(owner, taskID)
+			require.NoError(t, err)
+			return events
+		}
+
+		parent, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Parent", Tags: []string{"later"}})
+		require.NoError(t, err)
+		subtask, err := taskService.CreateSubtask(owner, parent.ID, &models.CreateTaskRequest{Title: "Subtask"})
+		require.NoError(t, err)
+
+		comment, err := commentService.CreateComment(owner, parent.ID, &models.CommentRequest{Body: "Noted"})
+		require.NoError(t, err)
+		require.NoError(t, commentService.DeleteComment(owner, parent.ID, comment.ID))
+		history := events(parent.ID)
+		require.Len(t, history, 3)
+		assert.Equal(t, models.FieldChange{After: float64(comment.ID)}, history[1].Changes["comments"])
+		assert.Equal(t, models.FieldChange{Before: float64(comment.ID)}, history[2].Changes["comments"])
+
+		tags, err := tagService.GetTags(owner)
+		require.NoError(t, err)
+		for _, tag := range tags {
+			if tag.Name == "later" {
+				require.NoError(t, tagService.DeleteTag(owner, tag.ID))
+			}
+		}
+		history = events(parent.ID)
+		require.Len(t, history, 4)
+		assert.Equal(t, models.FieldChange{Before: []interface{}{"later"}, After: []interface{}{}}, history[3].Changes["tags"])
+
+		_, err = taskService.UpdateTask(owner, parent.ID, &models.UpdateTaskRequest{ProjectID: &project.ID})
+		require.NoError(t, err)
+		history = events(subtask.ID)
+		require.Len(t, history, 2)
+		assert.Equal(t, models.OperationUpdate, history[1].Operation)
+		assert.Equal(t, float64(project.ID), history[1].Changes["projectId"].After)
+
+		_, err = taskService.AssignTask(owner, subtask.ID, editor)
+		require.NoError(t, err)
+		require.NoError(t, projectService.RemoveMember(owner, project.ID, editor))
+		history = events(subtask.ID)
+		require.Len(t, history, 4)
+		assert.Equal(t, models.OperationUnassign, history[3].Operation)
+		assert.Equal(t, owner, history[3].ActorID)
+		assert.Equal(t, models.FieldChange{Before: float64(editor)}, history[3].Changes["assigneeId"])
+
+		require.NoError(t, projectService.DeleteProject(owner, project.ID))
+		var deletes int64
+		require.NoError(t, db.Model(&models.TaskEvent{}).
+			Where("task_id IN ? AND operation = ?", []uint{parent.ID, subtask.ID}, models.OperationDelete).
+			Count(&deletes).Error)
+		assert.Equal(t, int64(2), deletes)
+	})
+}
+
+func TestTaskVersions(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	commentService := services.NewCommentService(db, taskService)
+
+	task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Versioned"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), task.Version)
+
+	t.Run("should advance the version on every write", func(t *testing.T) {
+		updated, err := taskService.UpdateTask(1, task.ID, &models.UpdateTaskRequest{Title: stringPtr("Renamed")})
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), updated.Version)
+
+		completed, err := taskService.MarkTaskAsCompleted(1, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), completed.Version)
+
+		_, err = commentService.CreateComment(1, task.ID, &models.CommentRequest{Body: "Done"})
+		require.NoError(t, err)
+		found, err := taskService.GetTaskByID(1, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uint(4), found.Version)
+	})
+
+	t.Run("should reject updates based on a stale version", func(t *testing.T) {
+		stale := uint(2)
+		_, err := taskService.UpdateTask(1, task.ID, &models.UpdateTaskRequest{Title: stringPtr("Lost update"), ExpectedVersion: &stale})
+		assert.EqualError(t, err, "version mismatch")
+
+		found, err := taskService.GetTaskByID(1, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", found.Title)
+
+		current := found.Version
+		updated, err := taskService.UpdateTask(1, task.ID, &models.UpdateTaskRequest{Title: stringPtr("Kept"), ExpectedVersion: &current})
+		require.NoError(t, err)
+		assert.Equal(t, current+1, updated.Version)
+	})
+
+	t.Run("should advance the version when what a task shows changes", func(t *testing.T) {
+		tagService := services.NewTagService(db, taskService)
+		version := func(taskID uint) uint {
+			found, err := taskService.GetTaskByID(1, taskID)
+			require.NoError(t, err)
+			return found.Version
+		}
+
+		parent, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Parent"})
+		require.NoError(t, err)
+		subtask, err := taskService.CreateSubtask(1, parent.ID, &models.CreateTaskRequest{Title: "Step", Tags: []string{"step"}})
+		require.NoError(t, err)
+		assert.Equal(t, parent.Version+1, version(parent.ID))
+
+		current := version(parent.ID)
+		_, err = taskService.UpdateTask(1, subtask.ID, &models.UpdateTaskRequest{Title: stringPtr("First step")})
+		require.NoError(t, err)
+		assert.Equal(t, current+1, version(parent.ID))
+
+		blocker, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Blocker"})
+		require.NoError(t, err)
+		current = version(parent.ID)
+		_, err = taskService.AddDependency(1, parent.ID, blocker.ID)
+		require.NoError(t, err)
+		assert.Equal(t, current+1, version(parent.ID))
+
+		current = version(parent.ID)
+		_, err = taskService.MarkTaskAsCompleted(1, blocker.ID)
+		require.NoError(t, err)
+		assert.Equal(t, current+1, version(parent.ID))
+
+		tags, err := tagService.GetTags(1)
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		current, currentSubtask := version(parent.ID), version(subtask.ID)
+		_, err = tagService.UpdateTag(1, tags[0].ID, &models.UpdateTagRequest{Name: stringPtr("first")})
+		require.NoError(t, err)
+		assert.Equal(t, current+1, version(parent.ID))
+		assert.Equal(t, currentSubtask+1, version(subtask.ID))
+	})
+}
+
+func TestRunBatch(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+
+	existing, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Existing"})
+	require.NoError(t, err)
+	others, err := taskService.CreateTask(2, &models.CreateTaskRequest{Title: "Someone else's"})
+	require.NoError(t, err)
+
+	count := func() int64 {
+		var total int64
+		require.NoError(t, db.Model(&models.Task{}).Count(&total).Error)
+		return total
+	}
+
+	operations := func() []models.BatchOperation {
+		return []models.BatchOperation{
+			{Op: models.BatchCreate, Create: &models.CreateTaskRequest{Title: "Created in batch"}},
+			{Op: models.BatchComplete, ID: existing.ID},
+			{Op: models.BatchDelete, ID: others.ID},
+			{Op: models.BatchUpdate, ID: existing.ID, Update: &models.UpdateTaskRequest{Title: stringPtr("Never applied")}},
+		}
+	}
+
+	t.Run("should roll back an atomic batch on the first failure", func(t *testing.T) {
+		tasks, errs, committed := taskService.RunBatch(1, operations(), true)
+		assert.False(t, committed)
+		assert.NoError(t, errs[0])
+		require.NotNil(t, tasks[0])
+		assert.NoError(t, errs[1])
+		assert.EqualError(t, errs[2], "task not found")
+		assert.EqualError(t, errs[3], "batch aborted")
+
+		assert.Equal(t, int64(2), count())
+		found, err := taskService.GetTaskByID(1, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusPending, found.Status)
+	})
+
+	t.Run("should apply what it can in best-effort mode", func(t *testing.T) {
+		tasks, errs, committed := taskService.RunBatch(1, operations(), false)
+		assert.True(t, committed)
+		assert.NoError(t, errs[0])
+		assert.NoError(t, errs[1])
+		assert.EqualError(t, errs[2], "task not found")
+		assert.NoError(t, errs[3])
+		assert.Equal(t, "Never applied", tasks[3].Title)
+
+		assert.Equal(t, int64(3), count())
+		found, err := taskService.GetTaskByID(1, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCompleted, found.Status)
+	})
+}
+
+func TestTaskCursorPagination(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+
+	for _, priority := range []models.TaskPriority{models.PriorityLow, models.PriorityHigh, models.PriorityMedium, models.PriorityHigh, models.PriorityLow} {
+		priority := priority
+		_, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Task " + string(priority), Priority: &priority})
+		require.NoError(t, err)
+	}
+
+	all, _, err := taskService.GetTasksByUser(1, &models.TaskFilter{Page: 1, Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+
+	ids := func(tasks []models.Task) []uint {
+		result := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			result = append(result, task.ID)
+		}
+		return result
+	}
+
+	t.Run("should walk every task forwards and back", func(t *testing.T) {
+		first, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[:2]), ids(first.Tasks))
+		require.NotNil(t, first.Total)
+		assert.Equal(t, int64(5), *first.Total)
+		assert.Empty(t, first.PrevCursor)
+
+		second, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[2:4]), ids(second.Tasks))
+		assert.Nil(t, second.Total)
+
+		last, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Cursor: second.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[4:]), ids(last.Tasks))
+		assert.Empty(t, last.NextCursor)
+
+		back, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Cursor: last.PrevCursor})
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[2:4]), ids(back.Tasks))
+
+		start, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Cursor: back.PrevCursor})
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[:2]), ids(start.Tasks))
+		assert.Empty(t, start.PrevCursor)
+		assert.NotEmpty(t, start.NextCursor)
+	})
+
+	t.Run("should not shift pages when tasks are added", func(t *testing.T) {
+		first, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2})
+		require.NoError(t, err)
+
+		urgent := models.PriorityHigh
+		_, err = taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Urgent", Priority: &urgent})
+		require.NoError(t, err)
+
+		second, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[2:4]), ids(second.Tasks))
+	})
+
+	t.Run("should count the total on request", func(t *testing.T) {
+		first, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Count: boolPtr(false)})
+		require.NoError(t, err)
+		assert.Nil(t, first.Total)
+
+		next, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Cursor: first.NextCursor, Count: boolPtr(true)})
+		require.NoError(t, err)
+		require.NotNil(t, next.Total)
+		assert.Equal(t, int64(6), *next.Total)
+	})
+
+	t.Run("should reject invalid cursors", func(t *testing.T) {
+		_, err := taskService.ListTasks(1, &models.TaskFilter{Page: 1, Limit: 2, Cursor: "not-a-cursor"})
+		assert.EqualError(t, err, "invalid cursor")
+	})
+}
+
+func TestTaskSorting(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+
+	now := time.Now().Truncate(time.Second)
+	tomorrow, nextWeek := now.Add(24*time.Hour), now.Add(7*24*time.Hour)
+	create := func(title string, dueDate *time.Time, position int) uint {
+		task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: title, DueDate: dueDate, Position: &position})
+		require.NoError(t, err)
+		return task.ID
+	}
+	someday := create("Someday", nil, 2)
+	later := create("Later", &nextWeek, 0)
+	soon := create("Soon", &tomorrow, 3)
+	whenever := create("Whenever", nil, 1)
+
+	// walk pages the whole list through cursors, two tasks at a time
+	walk := func(sort string) []uint {
+		var ids []uint
+		filter := &models.TaskFilter{Sort: sort, Page: 1, Limit: 2}
+		for {
+			page, err := taskService.ListTasks(1, filter)
+			require.NoError(t, err)
+			for _, task := range page.Tasks {
+				ids = append(ids, task.ID)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			filter.Cursor = page.NextCursor
+		}
+	}
+
+	t.Run("should sort due dates with nulls last in both directions", func(t *testing.T) {
+		assert.Equal(t, []uint{soon, later, someday, whenever}, walk("dueDate"))
+		assert.Equal(t, []uint{later, soon, whenever, someday}, walk("-dueDate"))
+	})
+
+	t.Run("should page back across null due dates", func(t *testing.T) {
+		first, err := taskService.ListTasks(1, &models.TaskFilter{Sort: "dueDate", Page: 1, Limit: 2})
+		require.NoError(t, err)
+		second, err := taskService.ListTasks(1, &models.TaskFilter{Sort: "dueDate", Page: 1, Limit: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		back, err := taskService.ListTasks(1, &models.TaskFilter{Sort: "dueDate", Page: 1, Limit: 2, Cursor: second.PrevCursor})
+		require.NoError(t, err)
+		require.Len(t, back.Tasks, 2)
+		assert.Equal(t, []uint{soon, later}, []uint{back.Tasks[0].ID, back.Tasks[1].ID})
+		assert.Empty(t, back.PrevCursor)
+	})
+
+	t.Run("should sort by several fields", func(t *testing.T) {
+		assert.Equal(t, []uint{later, whenever, someday, soon}, walk("position"))
+		assert.Equal(t, []uint{whenever, soon, someday, later}, walk("-title,priority"))
+	})
+
+	t.Run("should reject cursors taken under another sort", func(t *testing.T) {
+		page, err := taskService.ListTasks(1, &models.TaskFilter{Sort: "title", Page: 1, Limit: 2})
+		require.NoError(t, err)
+		_, err = taskService.ListTasks(1, &models.TaskFilter{Sort: "position", Page: 1, Limit: 2, Cursor: page.NextCursor})
+		assert.EqualError(t, err, "invalid cursor")
+	})
+
+	t.Run("should reject unknown sort fields", func(t *testing.T) {
+		_, err := taskService.ListTasks(1, &models.TaskFilter{Sort: "title;DROP TABLE tasks", Page: 1, Limit: 2})
+		assert.Error(t, err)
+	})
+}
+
+func TestTaskFilterExpressions(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+
+	high, low := models.PriorityHigh, models.PriorityLow
+	now := time.Now()
+	nextWeek, yesterday := now.AddDate(0, 0, 7), now.AddDate(0, 0, -1)
+
+	launch, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Plan launch", Priority: &high, DueDate: &nextWeek, Tags: []string{"work"}})
+	require.NoError(t, err)
+	someday, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Read a book", Priority: &low})
+	require.NoError(t, err)
+	_, err = taskService.MarkTaskAsCompleted(1, someday.ID)
+	require.NoError(t, err)
+	report, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Weekly REPORT", DueDate: &yesterday})
+	require.NoError(t, err)
+
+	list := func(expression string) []uint {
+		tasks, _, err := taskService.GetTasksByUser(1, &models.TaskFilter{Filter: expression, Sort: "title", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		ids := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		expression string
+		want       []uint
+	}{
+		{"priority:high AND (due<today OR status:pending)", []uint{launch.ID}},
+		{"priority:high,low", []uint{launch.ID, someday.ID}},
+		{"due:none", []uint{someday.ID}},
+		{"due:overdue", []uint{report.ID}},
+		{"NOT due<today", []uint{launch.ID, someday.ID}},
+		{"title:report OR tag:work", []uint{launch.ID, report.ID}},
+		{"tag:Work", []uint{launch.ID}},
+		{"status:completed OR created>=today", []uint{launch.ID, someday.ID, report.ID}},
+		{"updated<yesterday", []uint{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			assert.Equal(t, tt.want, list(tt.expression))
+		})
+	}
+}
+
+func TestSavedViews(t *testing.T) {
+	db := newTestDB(t)
+	viewService := services.NewViewService(db)
+
+	status := models.StatusPending
+	view, err := viewService.CreateView(1, &models.SavedViewRequest{
+		Name:   "Focus",
+		Filter: models.ViewFilter{Status: &status, Filter: "priority:high"},
+		Sort:   "dueDate",
+	})
+	require.NoError(t, err)
+
+	t.Run("should store the filter", func(t *testing.T) {
+		found, err := viewService.GetView(1, view.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.Filter.Status)
+		assert.Equal(t, models.StatusPending, *found.Filter.Status)
+		assert.Equal(t, "priority:high", found.Filter.Filter)
+		assert.Equal(t, "dueDate", found.Sort)
+	})
+
+	t.Run("should keep views private", func(t *testing.T) {
+		_, err := viewService.GetView(2, view.ID)
+		assert.EqualError(t, err, "view not found")
+		views, err := viewService.GetViews(2)
+		require.NoError(t, err)
+		assert.Empty(t, views)
+	})
+
+	t.Run("should reject duplicate names per user", func(t *testing.T) {
+		_, err := viewService.CreateView(1, &models.SavedViewRequest{Name: "Focus"})
+		assert.EqualError(t, err, "view already exists")
+		_, err = viewService.CreateView(2, &models.SavedViewRequest{Name: "Focus"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should replace the filter on update", func(t *testing.T) {
+		updated, err := viewService.UpdateView(1, view.ID, &models.UpdateSavedViewRequest{Filter: &models.ViewFilter{Tags: "work"}})
+		require.NoError(t, err)
+		assert.Nil(t, updated.Filter.Status)
+		assert.Equal(t, "work", updated.Filter.Tags)
+		assert.Equal(t, "dueDate", updated.Sort)
+	})
+
+	t.Run("should delete views", func(t *testing.T) {
+		require.NoError(t, viewService.DeleteView(1, view.ID))
+		_, err := viewService.GetView(1, view.ID)
+		assert.EqualError(t, err, "view not found")
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+
+	create := func(title string, priority models.TaskPriority) *models.Task {
+		task, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: title, Priority: &priority})
+		require.NoError(t, err)
+		return task
+	}
+	design := create("Design", models.PriorityMedium)
+	build := create("Build", models.PriorityMedium)
+	docs := create("Docs", models.PriorityLow)
+	release := create("Release", models.PriorityHigh)
+
+	_, err := taskService.AddDependency(1, build.ID, design.ID)
+	require.NoError(t, err)
+	_, err = taskService.AddDependency(1, release.ID, build.ID)
+	require.NoError(t, err)
+	_, err = taskService.AddDependency(1, release.ID, docs.ID)
+	require.NoError(t, err)
+
+	t.Run("should reject cycles and duplicates", func(t *testing.T) {
+		_, err := taskService.AddDependency(1, design.ID, release.ID)
+		assert.EqualError(t, err, "dependency would create a cycle")
+		_, err = taskService.AddDependency(1, design.ID, design.ID)
+		assert.EqualError(t, err, "task cannot block itself")
+		_, err = taskService.AddDependency(1, build.ID, design.ID)
+		assert.EqualError(t, err, "dependency already exists")
+		_, err = taskService.AddDependency(2, docs.ID, design.ID)
+		assert.EqualError(t, err, "task not found")
+	})
+
+	t.Run("should type the cycle check seed for Postgres", func(t *testing.T) {
+		var queries []string
+		require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:record_row", func(tx *gorm.DB) {
+			queries = append(queries, tx.Statement.SQL.String())
+		}))
+		defer db.Callback().Row().Remove("test:record_row")
+
+		_, err := taskService.AddDependency(1, docs.ID, design.ID)
+		require.NoError(t, err)
+		require.NoError(t, taskService.RemoveDependency(1, docs.ID, design.ID))
+		var cycleCheck string
+		for _, query := range queries {
+			if strings.Contains(query, "WITH RECURSIVE chain") {
+				cycleCheck = query
+			}
+		}
+		// SQLite ignores column types, so only the SQL text shows the recursive CTE is valid on Postgres
+		assert.Contains(t, cycleCheck, "SELECT CAST(? AS BIGINT)\n\tUNION")
+	})
+
+	t.Run("should flag blocked tasks", func(t *testing.T) {
+		found, err := taskService.GetTaskByID(1, build.ID)
+		require.NoError(t, err)
+		assert.True(t, found.IsBlocked)
+		found, err = taskService.GetTaskByID(1, design.ID)
+		require.NoError(t, err)
+		assert.False(t, found.IsBlocked)
+
+		deps, err := taskService.GetDependencies(1, release.ID)
+		require.NoError(t, err)
+		assert.Len(t, deps.BlockedBy, 2)
+		assert.Empty(t, deps.Blocking)
+	})
+
+	t.Run("should plan stages in dependency order", func(t *testing.T) {
+		plan, err := taskService.GetPlan(1, []uint{release.ID, docs.ID, design.ID})
+		require.NoError(t, err)
+		require.Len(t, plan.Stages, 2)
+		// Release waits on Design through Build, which is not part of the plan
+		assert.Equal(t, []uint{design.ID, docs.ID}, []uint{plan.Stages[0][0].ID, plan.Stages[0][1].ID})
+		assert.Equal(t, release.ID, plan.Stages[1][0].ID)
+
+		_, err = taskService.GetPlan(2, []uint{release.ID})
+		assert.EqualError(t, err, "task not found")
+	})
+
+	t.Run("should refuse to complete a blocked task unless forced", func(t *testing.T) {
+		_, err := taskService.MarkTaskAsCompleted(1, build.ID)
+		assert.EqualError(t, err, "task is blocked")
+		completed := models.StatusCompleted
+		_, err = taskService.UpdateTask(1, build.ID, &models.UpdateTaskRequest{Status: &completed})
+		assert.EqualError(t, err, "task is blocked")
+
+		forced, err := taskService.CompleteTask(1, docs.ID, true)
+		require.NoError(t, err)
+		assert.True(t, forced.IsCompleted())
+
+		_, err = taskService.MarkTaskAsCompleted(1, design.ID)
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(1, build.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should remove dependencies", func(t *testing.T) {
+		require.NoError(t, taskService.RemoveDependency(1, release.ID, docs.ID))
+		assert.EqualError(t, taskService.RemoveDependency(1, release.ID, docs.ID), "dependency not found")
+	})
+}
+
+func TestTrash(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	commentService := services.NewCommentService(db, taskService)
+
+	parent, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Parent", Tags: []string{"work"}})
+	require.NoError(t, err)
+	subtask, err := taskService.CreateSubtask(1, parent.ID, &models.CreateTaskRequest{Title: "Step"})
+	require.NoError(t, err)
+	_, err = commentService.CreateComment(1, parent.ID, &models.CommentRequest{Body: "Note"})
+	require.NoError(t, err)
+	other, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Other"})
+	require.NoError(t, err)
+
+	require.NoError(t, taskService.DeleteTask(1, parent.ID))
+
+	t.Run("should list trashed tasks without their subtasks", func(t *testing.T) {
+		trash, total, err := taskService.GetTrash(1, &models.TrashFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, trash, 1)
+		assert.Equal(t, parent.ID, trash[0].ID)
+		assert.False(t, trash[0].DeletedAt.IsZero())
+
+		trash, _, err = taskService.GetTrash(2, &models.TrashFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("should exclude trashed tasks from stats", func(t *testing.T) {
+		stats, err := taskService.GetTaskStats(1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.Total)
+		assert.Equal(t, int64(0), stats.Subtasks)
+		require.Len(t, stats.ByTag, 1)
+		assert.Equal(t, int64(0), stats.ByTag[0].Total)
+	})
+
+	t.Run("should restore a task with its subtasks and comments", func(t *testing.T) {
+		_, err := taskService.RestoreTask(1, subtask.ID)
+		assert.EqualError(t, err, "parent task is deleted")
+
+		restored, err := taskService.RestoreTask(1, parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, parent.ID, restored.ID)
+
+		_, err = taskService.GetTaskByID(1, subtask.ID)
+		assert.NoError(t, err)
+		page, err := commentService.GetComments(1, parent.ID, &models.CommentFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, page.Comments, 1)
+
+		_, err = taskService.RestoreTask(1, parent.ID)
+		assert.EqualError(t, err, "task is not deleted")
+	})
+
+	t.Run("should not restore subtasks deleted earlier", func(t *testing.T) {
+		require.NoError(t, taskService.DeleteTask(1, subtask.ID))
+		require.NoError(t, taskService.DeleteTask(1, parent.ID))
+		_, err := taskService.RestoreTask(1, parent.ID)
+		require.NoError(t, err)
+		_, err = taskService.GetTaskByID(1, subtask.ID)
+		assert.EqualError(t, err, "task not found")
+	})
+
+	t.Run("should purge permanently", func(t *testing.T) {
+		require.NoError(t, taskService.PurgeTask(1, parent.ID))
+		var remaining int64
+		require.NoError(t, db.Unscoped().Model(&models.Task{}).Where("id IN ?", []uint{parent.ID, subtask.ID}).Count(&remaining).Error)
+		assert.Equal(t, int64(0), remaining)
+		assert.EqualError(t, taskService.PurgeTask(1, parent.ID), "task not found")
+	})
+
+	t.Run("should record and publish purging a live task", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		taskService.SetPublisher(publisher)
+		defer taskService.SetPublisher(nil)
+
+		live, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Live"})
+		require.NoError(t, err)
+		previous, err := taskService.CreateTask(1, &models.CreateTaskRequest{Title: "Previous"})
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.Task{}).Where("id = ?", previous.ID).Update("next_occurrence_id", live.ID).Error)
+		require.NoError(t, db.Create(&models.Notification{UserID: 1, Key: "purge-test", Type: "task.created", Title: "Live", TaskID: &live.ID}).Error)
+		publisher.events = nil
+
+		require.NoError(t, taskService.PurgeTask(1, live.ID))
+
+		var last models.TaskEvent
+		require.NoError(t, db.Where("task_id = ?", live.ID).Order("id DESC").First(&last).Error)
+		assert.Equal(t, models.OperationDelete, last.Operation)
+		assert.Equal(t, "Live", last.Changes["title"].Before)
+		require.Len(t, publisher.events, 1)
+		assert.Equal(t, "task.deleted", publisher.events[0].eventType)
+		assert.Equal(t, []uint{1}, publisher.events[0].userIDs)
+		assert.Nil(t, publisher.events[0].change.Task)
+		var outbox int64
+		require.NoError(t, db.Model(&models.OutboxEvent{}).Where("type = ?", "task.deleted").Count(&outbox).Error)
+		assert.NotZero(t, outbox)
+
+		var reloaded models.Task
+		require.NoError(t, db.First(&reloaded, previous.ID).Error)
+		assert.Nil(t, reloaded.NextOccurrenceID)
+		var notification models.Notification
+		require.NoError(t, db.Where("key = ?", "purge-test").First(&notification).Error)
+		assert.Nil(t, notification.TaskID)
+	})
+
+	t.Run("should purge trash past the retention period", func(t *testing.T) {
+		require.NoError(t, taskService.DeleteTask(1, other.ID))
+		purged, err := taskService.PurgeTrash(time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		purged, err = taskService.PurgeTrash(time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		trash, _, err := taskService.GetTrash(1, &models.TrashFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+}
+
+// publishedEvent is a task change captured by recordingPublisher
+type publishedEvent struct {
+	userIDs   []uint
+	eventType string
+	change    models.TaskChange
+}
+
+type recordingPublisher struct {
+	events []publishedEvent
+}
+
+func (p *recordingPublisher) Publish(userIDs []uint, eventType string, data interface{}) {
+	p.events = append(p.events, publishedEvent{userIDs: userIDs, eventType: eventType, change: data.(models.TaskChange)})
+}
+
+func TestTaskPublishing(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	projectService := services.NewProjectService(db, taskService)
+	userService := services.NewUserService(db)
+	publisher := &recordingPublisher{}
+	taskService.SetPublisher(publisher)
+
+	register := func(email string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: email, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	owner := register("owner@example.com")
+	member := register("member@example.com")
+	project, err := projectService.CreateProject(owner, &models.CreateProjectRequest{Name: "Team"})
+	require.NoError(t, err)
+	_, err = projectService.AddMember(owner, project.ID, &models.AddMemberRequest{Email: "member@example.com", Role: models.RoleEditor})
+	require.NoError(t, err)
+
+	t.Run("should publish committed changes to everyone who can see the task", func(t *testing.T) {
+		publisher.events = nil
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Shared", ProjectID: &project.ID})
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(member, task.ID)
+		require.NoError(t, err)
+
+		require.Len(t, publisher.events, 2)
+		assert.Equal(t, "task.created", publisher.events[0].eventType)
+		assert.ElementsMatch(t, []uint{owner, member}, publisher.events[0].userIDs)
+		completed := publisher.events[1]
+		assert.Equal(t, "task.completed", completed.eventType)
+		assert.Equal(t, member, completed.change.ActorID)
+		require.NotNil(t, completed.change.Task)
+		assert.Equal(t, models.StatusCompleted, completed.change.Task.Status)
+	})
+
+	t.Run("should publish private changes to the creator only", func(t *testing.T) {
+		publisher.events = nil
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Private"})
+		require.NoError(t, err)
+		require.NoError(t, taskService.DeleteTask(owner, task.ID))
+
+		require.Len(t, publisher.events, 2)
+		assert.Equal(t, []uint{owner}, publisher.events[1].userIDs)
+		assert.Equal(t, "task.deleted", publisher.events[1].eventType)
+		assert.Nil(t, publisher.events[1].change.Task)
+	})
+
+	t.Run("should not publish rolled back changes", func(t *testing.T) {
+		publisher.events = nil
+		_, _, committed := taskService.RunBatch(owner, []models.BatchOperation{
+			{Op: models.BatchCreate, Create: &models.CreateTaskRequest{Title: "Rolled back"}},
+			{Op: models.BatchComplete, ID: 9999},
+		}, true)
+		assert.False(t, committed)
+		assert.Empty(t, publisher.events)
+
+		_, _, committed = taskService.RunBatch(owner, []models.BatchOperation{
+			{Op: models.BatchCreate, Create: &models.CreateTaskRequest{Title: "First"}},
+			{Op: models.BatchCreate, Create: &models.CreateTaskRequest{Title: "Second"}},
+		}, true)
+		assert.True(t, committed)
+		assert.Len(t, publisher.events, 2)
+	})
+
+	t.Run("should publish task changes made by other services", func(t *testing.T) {
+		commentService := services.NewCommentService(db, taskService)
+		tagService := services.NewTagService(db, taskService)
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Team task", ProjectID: &project.ID, Tags: []string{"team"}})
+		require.NoError(t, err)
+		_, err = taskService.AssignTask(owner, task.ID, member)
+		require.NoError(t, err)
+
+		publisher.events = nil
+		_, err = commentService.CreateComment(member, task.ID, &models.CommentRequest{Body: "On it"})
+		require.NoError(t, err)
+		tags, err := tagService.GetTags(owner)
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		require.NoError(t, tagService.DeleteTag(owner, tags[0].ID))
+		require.NoError(t, projectService.RemoveMember(owner, project.ID, member))
+		require.NoError(t, projectService.DeleteProject(owner, project.ID))
+
+		require.Greater(t, len(publisher.events), 3)
+		for i, eventType := range []string{"task.updated", "task.updated", "task.unassigned"} {
+			assert.Equal(t, eventType, publisher.events[i].eventType)
+			assert.Equal(t, task.ID, publisher.events[i].change.TaskID)
+		}
+		// The shared task created earlier is deleted along with this one
+		for _, event := range publisher.events[3:] {
+			assert.Equal(t, "task.deleted", event.eventType)
+			assert.Equal(t, []uint{owner}, event.userIDs)
+		}
+
+		var outboxed int64
+		require.NoError(t, db.Model(&models.OutboxEvent{}).Where("type = ?", "task.unassigned").Count(&outboxed).Error)
+		assert.Equal(t, int64(1), outboxed)
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	webhookService := services.NewWebhookService(db, 5*time.Second, 2)
+	// The test receivers listen on loopback
+	webhookService.SetAllowPrivateTargets(true)
+	outbox := services.NewOutboxRelay(db, 3)
+	outbox.AddSink("webhooks", webhookService)
+	userService := services.NewUserService(db)
+
+	register := func(email string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: email, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	owner := register("owner@example.com")
+	other := register("other@example.com")
+
+	var received []*http.Request
+	var bodies [][]byte
+	status := http.StatusOK
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	relay := func() {
+		_, err := outbox.RelayDue(context.Background(), 100)
+		require.NoError(t, err)
+	}
+	deliveries := func(webhookID uint) []models.WebhookDelivery {
+		list, _, err := webhookService.GetDeliveries(owner, webhookID, &models.DeliveryFilter{Page: 1, Limit: 100})
+		require.NoError(t, err)
+		return list
+	}
+
+	webhook, err := webhookService.CreateWebhook(owner, &models.WebhookRequest{URL: receiver.URL, Events: []string{"task.completed"}})
+	require.NoError(t, err)
+	assert.True(t, webhook.Active)
+	assert.NotEmpty(t, webhook.Secret)
+	_, err = webhookService.CreateWebhook(other, &models.WebhookRequest{URL: receiver.URL, Events: []string{"task.completed"}})
+	require.NoError(t, err)
+
+	t.Run("should queue selected events for webhooks of users who can see the task", func(t *testing.T) {
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Ship release"})
+		require.NoError(t, err)
+		relay()
+		assert.Empty(t, deliveries(webhook.ID))
+
+		_, err = taskService.MarkTaskAsCompleted(owner, task.ID)
+		require.NoError(t, err)
+		assert.Empty(t, deliveries(webhook.ID), "deliveries are queued by the relay")
+		relay()
+
+		queued := deliveries(webhook.ID)
+		require.Len(t, queued, 1)
+		assert.Equal(t, "task.completed", queued[0].EventType)
+		assert.Equal(t, models.DeliveryPending, queued[0].Status)
+
+		var total int64
+		require.NoError(t, db.Model(&models.WebhookDelivery{}).Count(&total).Error)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("should deliver signed payloads", func(t *testing.T) {
+		received, bodies = nil, nil
+		attempted, err := webhookService.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+		require.Len(t, received, 1)
+
+		req := received[0]
+		assert.Equal(t, "task.completed", req.Header.Get("X-Webhook-Event"))
+		timestamp, err := strconv.ParseInt(req.Header.Get("X-Webhook-Timestamp"), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256="+services.WebhookSignature(webhook.Secret, timestamp, bodies[0]), req.Header.Get("X-Webhook-Signature"))
+
+		assert.Equal(t, *deliveries(webhook.ID)[0].IdempotencyKey, req.Header.Get("Idempotency-Key"))
+		var payload models.EventPayload
+		require.NoError(t, json.Unmarshal(bodies[0], &payload))
+		assert.Equal(t, "task.completed", payload.Type)
+		require.NotNil(t, payload.Data.Task)
+		assert.Equal(t, "Ship release", payload.Data.Task.Title)
+
+		delivered := deliveries(webhook.ID)[0]
+		assert.Equal(t, models.DeliverySucceeded, delivered.Status)
+		assert.Equal(t, 1, delivered.Attempts)
+		require.NotNil(t, delivered.ResponseStatus)
+		assert.Equal(t, http.StatusOK, *delivered.ResponseStatus)
+
+		attempted, err = webhookService.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Zero(t, attempted)
+	})
+
+	t.Run("should back off and dead-letter failed deliveries", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		defer func() { status = http.StatusOK }()
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Flaky"})
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(owner, task.ID)
+		require.NoError(t, err)
+		relay()
+
+		_, err = webhookService.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		failed := deliveries(webhook.ID)[0]
+		assert.Equal(t, models.DeliveryPending, failed.Status)
+		assert.Equal(t, 1, failed.Attempts)
+		assert.Contains(t, failed.LastError, "500")
+		assert.WithinDuration(t, time.Now().Add(30*time.Second), failed.NextAttemptAt, 5*time.Second)
+
+		attempted, err := webhookService.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Zero(t, attempted, "the retry is not due yet")
+
+		require.NoError(t, db.Model(&models.WebhookDelivery{}).Where("id = ?", failed.ID).
+			Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+		_, err = webhookService.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		dead := deliveries(webhook.ID)[0]
+		assert.Equal(t, models.DeliveryDead, dead.Status)
+		assert.Equal(t, 2, dead.Attempts)
+
+		retried, err := webhookService.RetryDelivery(owner, webhook.ID, dead.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DeliveryPending, retried.Status)
+		assert.Zero(t, retried.Attempts)
+		_, err = webhookService.RetryDelivery(owner, webhook.ID, dead.ID)
+		assert.EqualError(t, err, "delivery is not dead")
+	})
+
+	t.Run("should hold deliveries of inactive webhooks", func(t *testing.T) {
+		inactive := false
+		_, err := webhookService.UpdateWebhook(owner, webhook.ID, &models.UpdateWebhookRequest{Active: &inactive})
+		require.NoError(t, err)
+		attempted, err := webhookService.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Zero(t, attempted)
+
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Quiet"})
+		require.NoError(t, err)
+		before := len(deliveries(webhook.ID))
+		_, err = taskService.MarkTaskAsCompleted(owner, task.ID)
+		require.NoError(t, err)
+		relay()
+		assert.Len(t, deliveries(webhook.ID), before)
+	})
+
+	t.Run("should refuse private targets", func(t *testing.T) {
+		strict := services.NewWebhookService(db, 5*time.Second, 2)
+		for _, url := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://169.254.169.254/latest", "http://10.1.2.3/", "http://[::1]/", "http://0.0.0.0/"} {
+			_, err := strict.CreateWebhook(owner, &models.WebhookRequest{URL: url, Events: []string{"task.completed"}})
+			assert.EqualError(t, err, "webhook URL must resolve to a public address", url)
+		}
+
+		public, err := strict.CreateWebhook(owner, &models.WebhookRequest{URL: "http://203.0.113.10/hook", Events: []string{"task.completed"}})
+		require.NoError(t, err)
+		private := "http://192.168.1.5/hook"
+		_, err = strict.UpdateWebhook(owner, public.ID, &models.UpdateWebhookRequest{URL: &private})
+		assert.EqualError(t, err, "webhook URL must resolve to a public address")
+
+		// A name that resolves differently by delivery time is caught when connecting
+		require.NoError(t, db.Model(&models.Webhook{}).Where("id = ?", public.ID).Update("url", receiver.URL).Error)
+		received = nil
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Rebound"})
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(owner, task.ID)
+		require.NoError(t, err)
+		relay()
+		attempted, err := strict.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+		assert.Empty(t, received)
+		refused := deliveries(public.ID)[0]
+		assert.Equal(t, models.DeliveryPending, refused.Status)
+		assert.Contains(t, refused.LastError, "public address")
+		require.NoError(t, strict.DeleteWebhook(owner, public.ID))
+	})
+
+	t.Run("should not follow redirects", func(t *testing.T) {
+		redirector := httptest.NewServer(http.RedirectHandler(receiver.URL, http.StatusFound))
+		defer redirector.Close()
+		redirected, err := webhookService.CreateWebhook(owner, &models.WebhookRequest{URL: redirector.URL, Events: []string{"task.completed"}})
+		require.NoError(t, err)
+
+		received = nil
+		task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Moved"})
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(owner, task.ID)
+		require.NoError(t, err)
+		relay()
+		_, err = webhookService.DeliverDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Empty(t, received)
+		failed := deliveries(redirected.ID)[0]
+		require.NotNil(t, failed.ResponseStatus)
+		assert.Equal(t, http.StatusFound, *failed.ResponseStatus)
+		require.NoError(t, webhookService.DeleteWebhook(owner, redirected.ID))
+	})
+
+	t.Run("should keep webhooks private to their owner", func(t *testing.T) {
+		_, err := webhookService.GetWebhook(other, webhook.ID)
+		assert.EqualError(t, err, "webhook not found")
+		require.NoError(t, webhookService.DeleteWebhook(owner, webhook.ID))
+		_, _, err = webhookService.GetDeliveries(owner, webhook.ID, &models.DeliveryFilter{Page: 1, Limit: 10})
+		assert.EqualError(t, err, "webhook not found")
+	})
+}
+
+type recordingSink struct {
+	keys []string
+	err  error
+}
+
+func (s *recordingSink) HandleOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	s.keys = append(s.keys, event.IdempotencyKey)
+	return s.err
+}
+
+func TestOutbox(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	userService := services.NewUserService(db)
+	relay := services.NewOutboxRelay(db, 3)
+	taskService.SetOutboxRelay(relay)
+	first, second := &recordingSink{}, &recordingSink{}
+	relay.AddSink("first", first)
+	relay.AddSink("second", second)
+
+	user, err := userService.Register(&models.RegisterRequest{Email: "owner@example.com", Name: "Owner", Password: "password123"})
+	require.NoError(t, err)
+
+	pending := func() []models.OutboxEvent {
+		var events []models.OutboxEvent
+		require.NoError(t, db.Where("processed_at IS NULL").Order("id ASC").Find(&events).Error)
+		return events
+	}
+
+	t.Run("should write task events to the outbox with the change", func(t *testing.T) {
+		task, err := taskService.CreateTask(user.ID, &models.CreateTaskRequest{Title: "Outboxed"})
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(user.ID, task.ID)
+		require.NoError(t, err)
+
+		events := pending()
+		require.Len(t, events, 2)
+		assert.Equal(t, "task.created", events[0].Type)
+		assert.Equal(t, "task.completed", events[1].Type)
+		assert.Equal(t, models.UserIDs{user.ID}, events[1].Audience)
+
+		var payload models.EventPayload
+		require.NoError(t, json.Unmarshal([]byte(events[1].Payload), &payload))
+		assert.Equal(t, "task-event-"+strconv.FormatUint(uint64(payload.EventID), 10), events[1].IdempotencyKey)
+		require.NotNil(t, payload.Data.Task)
+		assert.Equal(t, models.StatusCompleted, payload.Data.Task.Status)
+
+		select {
+		case <-relay.Wake():
+		default:
+			t.Fatal("expected the relay to be woken")
+		}
+	})
+
+	t.Run("should not write rolled back changes", func(t *testing.T) {
+		before := len(pending())
+		_, _, committed := taskService.RunBatch(user.ID, []models.BatchOperation{
+			{Op: models.BatchCreate, Create: &models.CreateTaskRequest{Title: "Rolled back"}},
+			{Op: models.BatchComplete, ID: 9999},
+		}, true)
+		assert.False(t, committed)
+		assert.Len(t, pending(), before)
+	})
+
+	t.Run("should retry events until every sink accepts them", func(t *testing.T) {
+		second.err = errors.New("sink unavailable")
+		processed, err := relay.RelayDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, processed)
+
+		events := pending()
+		require.Len(t, events, 2)
+		assert.Equal(t, 1, events[0].Attempts)
+		assert.Contains(t, events[0].LastError, "second: sink unavailable")
+		assert.True(t, events[0].NextAttemptAt.After(time.Now()))
+
+		processed, err = relay.RelayDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Zero(t, processed, "retries wait for their backoff")
+
+		second.err = nil
+		require.NoError(t, db.Model(&models.OutboxEvent{}).Where("processed_at IS NULL").
+			Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+		processed, err = relay.RelayDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, processed)
+		assert.Empty(t, pending())
+
+		// The first sink saw every event twice, under the same keys
+		require.Len(t, first.keys, 4)
+		assert.Equal(t, first.keys[:2], first.keys[2:])
+		assert.Equal(t, first.keys[2:], second.keys[2:])
+	})
+
+	t.Run("should purge processed events", func(t *testing.T) {
+		purged, err := relay.PurgeProcessed(time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+	})
+
+	t.Run("should let sinks ignore repeated events", func(t *testing.T) {
+		webhookService := services.NewWebhookService(db, time.Second, 1)
+		webhook, err := webhookService.CreateWebhook(user.ID, &models.WebhookRequest{URL: "https://203.0.113.10/hook", Events: []string{"task.created"}})
+		require.NoError(t, err)
+		_, err = taskService.CreateTask(user.ID, &models.CreateTaskRequest{Title: "Once"})
+		require.NoError(t, err)
+
+		event := pending()[0]
+		require.NoError(t, webhookService.HandleOutboxEvent(context.Background(), &event))
+		require.NoError(t, webhookService.HandleOutboxEvent(context.Background(), &event))
+		deliveries, total, err := webhookService.GetDeliveries(user.ID, webhook.ID, &models.DeliveryFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, event.IdempotencyKey, *deliveries[0].IdempotencyKey)
+	})
+
+	t.Run("should stop relaying events after the last attempt", func(t *testing.T) {
+		failing := services.NewOutboxRelay(db, 2)
+		failing.AddSink("broken", &recordingSink{err: errors.New("sink unavailable")})
+		due := func() {
+			require.NoError(t, db.Model(&models.OutboxEvent{}).Where("processed_at IS NULL").
+				Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+		}
+
+		for attempt := 1; attempt <= 2; attempt++ {
+			processed, err := failing.RelayDue(context.Background(), 10)
+			require.NoError(t, err)
+			assert.Equal(t, 1, processed)
+			due()
+		}
+		events := pending()
+		require.Len(t, events, 1)
+		assert.Equal(t, 2, events[0].Attempts)
+		require.NotNil(t, events[0].DeadAt)
+		assert.Contains(t, events[0].LastError, "broken: sink unavailable")
+
+		processed, err := failing.RelayDue(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Zero(t, processed)
+		purged, err := relay.PurgeProcessed(time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Zero(t, purged, "dead events are kept")
+	})
+}
+
+type recordingNotifier struct {
+	keys []string
+	err  error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg *notify.Message) error {
+	n.keys = append(n.keys, msg.Key)
+	return n.err
+}
+
+func TestReminders(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	notificationService := services.NewNotificationService(db)
+	webhookService := services.NewWebhookService(db, time.Second, 1)
+	userService := services.NewUserService(db)
+	scheduler := services.NewReminderScheduler(db)
+	scheduler.AddNotifier(models.ChannelInApp, notify.NewInAppNotifier(db))
+	scheduler.AddNotifier(models.ChannelWebhook, webhookService)
+
+	register := func(email string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: email, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	owner := register("owner@example.com")
+	other := register("other@example.com")
+
+	notifications := func() []models.Notification {
+		var list []models.Notification
+		require.NoError(t, db.Where("user_id = ?", owner).Order("id ASC").Find(&list).Error)
+		return list
+	}
+
+	due := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Send invoices", DueDate: &due})
+	require.NoError(t, err)
+
+	var reminder *models.TaskReminder
+	t.Run("should schedule reminders from the due date", func(t *testing.T) {
+		reminder, err = taskService.AddReminder(owner, task.ID, 30)
+		require.NoError(t, err)
+		require.NotNil(t, reminder.RemindAt)
+		assert.True(t, reminder.RemindAt.Equal(due.Add(-30*time.Minute)))
+
+		_, err = taskService.AddReminder(owner, task.ID, 30)
+		assert.EqualError(t, err, "reminder already exists")
+		_, err = taskService.AddReminder(other, task.ID, 30)
+		assert.EqualError(t, err, "task not found")
+
+		undated, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Someday"})
+		require.NoError(t, err)
+		unscheduled, err := taskService.AddReminder(owner, undated.ID, 0)
+		require.NoError(t, err)
+		assert.Nil(t, unscheduled.RemindAt)
+	})
+
+	t.Run("should move reminders with the due date", func(t *testing.T) {
+		due = due.Add(time.Hour)
+		_, err := taskService.UpdateTask(owner, task.ID, &models.UpdateTaskRequest{DueDate: &due})
+		require.NoError(t, err)
+
+		reminders, err := taskService.GetReminders(owner, task.ID)
+		require.NoError(t, err)
+		require.Len(t, reminders, 1)
+		assert.True(t, reminders[0].RemindAt.Equal(due.Add(-30*time.Minute)))
+	})
+
+	t.Run("should send due reminders once", func(t *testing.T) {
+		sent, err := scheduler.SendDueReminders(context.Background(), due.Add(-time.Hour), 10)
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+
+		sent, err = scheduler.SendDueReminders(context.Background(), due.Add(-29*time.Minute), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		list := notifications()
+		require.Len(t, list, 1)
+		assert.Equal(t, notify.TypeReminder, list[0].Type)
+		assert.Contains(t, list[0].Title, "Send invoices")
+		require.NotNil(t, list[0].TaskID)
+		assert.Equal(t, task.ID, *list[0].TaskID)
+
+		sent, err = scheduler.SendDueReminders(context.Background(), due, 10)
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+		assert.Len(t, notifications(), 1)
+	})
+
+	t.Run("should skip reminders of tasks long overdue", func(t *testing.T) {
+		past := time.Now().Add(-3 * time.Hour)
+		late, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Long gone", DueDate: &past})
+		require.NoError(t, err)
+		_, err = taskService.AddReminder(owner, late.ID, 0)
+		require.NoError(t, err)
+
+		sent, err := scheduler.SendDueReminders(context.Background(), time.Now(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Len(t, notifications(), 1)
+
+		reminders, err := taskService.GetReminders(owner, late.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, reminders[0].SentAt)
+	})
+
+	t.Run("should send the daily digest once at the user's hour", func(t *testing.T) {
+		enabled, hour, zone := true, 8, "Europe/Berlin"
+		_, err := notificationService.UpdateSettings(owner, &models.NotificationSettingsRequest{DigestEnabled: &enabled, DigestHour: &hour, Timezone: &zone})
+		require.NoError(t, err)
+		invalid := "Mars/Olympus"
+		_, err = notificationService.UpdateSettings(owner, &models.NotificationSettingsRequest{Timezone: &invalid})
+		assert.EqualError(t, err, "invalid timezone")
+
+		day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+		_, err = taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "File taxes", DueDate: &day})
+		require.NoError(t, err)
+
+		// 07:30 in Berlin
+		sent, err := scheduler.SendDigests(context.Background(), time.Date(2026, 3, 10, 6, 30, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+
+		// 08:30 in Berlin
+		sent, err = scheduler.SendDigests(context.Background(), time.Date(2026, 3, 10, 7, 30, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		list := notifications()
+		require.Len(t, list, 2)
+		assert.Equal(t, notify.TypeDigest, list[1].Type)
+		assert.Contains(t, list[1].Body, "File taxes")
+		assert.NotContains(t, list[1].Body, "Send invoices", "tasks due after the day are left out")
+
+		sent, err = scheduler.SendDigests(context.Background(), time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+	})
+
+	t.Run("should send reminders to webhooks that select them", func(t *testing.T) {
+		webhook, err := webhookService.CreateWebhook(owner, &models.WebhookRequest{URL: "https://203.0.113.10/hook", Events: []string{notify.TypeReminder}})
+		require.NoError(t, err)
+		_, err = notificationService.UpdateSettings(owner, &models.NotificationSettingsRequest{Channels: []string{models.ChannelWebhook}})
+		require.NoError(t, err)
+
+		soon := time.Now().Add(10 * time.Minute)
+		hooked, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Call supplier", DueDate: &soon})
+		require.NoError(t, err)
+		_, err = taskService.AddReminder(owner, hooked.ID, 15)
+		require.NoError(t, err)
+
+		sent, err := scheduler.SendDueReminders(context.Background(), time.Now(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Len(t, notifications(), 2, "in-app notifications were turned off")
+
+		deliveries, _, err := webhookService.GetDeliveries(owner, webhook.ID, &models.DeliveryFilter{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, notify.TypeReminder, deliveries[0].EventType)
+	})
+
+	t.Run("should retry reminders a channel failed to deliver", func(t *testing.T) {
+		email := &recordingNotifier{err: errors.New("connection refused")}
+		scheduler.AddNotifier(models.ChannelEmail, email)
+		_, err := notificationService.UpdateSettings(owner, &models.NotificationSettingsRequest{Channels: []string{models.ChannelEmail}})
+		require.NoError(t, err)
+
+		soon := time.Now().Add(10 * time.Minute)
+		flaky, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Renew domain", DueDate: &soon})
+		require.NoError(t, err)
+		_, err = taskService.AddReminder(owner, flaky.ID, 15)
+		require.NoError(t, err)
+
+		now := time.Now()
+		sent, err := scheduler.SendDueReminders(context.Background(), now, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		require.Len(t, email.keys, 1)
+		reminders, err := taskService.GetReminders(owner, flaky.ID)
+		require.NoError(t, err)
+		assert.Nil(t, reminders[0].SentAt)
+
+		sent, err = scheduler.SendDueReminders(context.Background(), now.Add(30*time.Second), 10)
+		require.NoError(t, err)
+		assert.Zero(t, sent, "the reminder waits before it is retried")
+
+		email.err = nil
+		sent, err = scheduler.SendDueReminders(context.Background(), now.Add(2*time.Minute), 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		require.Len(t, email.keys, 2)
+		assert.Equal(t, email.keys[0], email.keys[1])
+		reminders, err = taskService.GetReminders(owner, flaky.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, reminders[0].SentAt)
+	})
+}
+
+func TestNotificationInbox(t *testing.T) {
+	db := newTestDB(t)
+	taskService := services.NewTaskService(db)
+	projectService := services.NewProjectService(db, taskService)
+	userService := services.NewUserService(db)
+	notificationService := services.NewNotificationService(db)
+	relay := services.NewOutboxRelay(db, 3)
+	relay.AddSink("notifications", notificationService)
+
+	register := func(email, name string) uint {
+		user, err := userService.Register(&models.RegisterRequest{Email: email, Name: name, Password: "password123"})
+		require.NoError(t, err)
+		return user.ID
+	}
+	owner := register("owner@example.com", "Olivia")
+	member := register("member@example.com", "Mateo")
+	viewer := register("viewer@example.com", "Vera")
+	project, err := projectService.CreateProject(owner, &models.CreateProjectRequest{Name: "Team"})
+	require.NoError(t, err)
+	for _, email := range []string{"member@example.com", "viewer@example.com"} {
+		_, err = projectService.AddMember(owner, project.ID, &models.AddMemberRequest{Email: email, Role: models.RoleEditor})
+		require.NoError(t, err)
+	}
+
+	relayAll := func() {
+		_, err := relay.RelayDue(context.Background(), 100)
+		require.NoError(t, err)
+	}
+	inbox := func(userID uint) ([]models.Notification, int64) {
+		list, _, unread, err := notificationService.GetNotifications(userID, &models.NotificationFilter{Page: 1, Limit: 100})
+		require.NoError(t, err)
+		return list, unread
+	}
+
+	task, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Draft budget", ProjectID: &project.ID})
+	require.NoError(t, err)
+
+	t.Run("should notify the creator and assignee of changes by others", func(t *testing.T) {
+		_, err := taskService.AssignTask(owner, task.ID, member)
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsCompleted(member, task.ID)
+		require.NoError(t, err)
+		relayAll()
+
+		list, unread := inbox(member)
+		require.Len(t, list, 1)
+		assert.Equal(t, int64(1), unread)
+		assert.Equal(t, "task.assigned", list[0].Type)
+		assert.Equal(t, `Olivia assigned "Draft budget" to you`, list[0].Title)
+		require.NotNil(t, list[0].ActorID)
+		assert.Equal(t, owner, *list[0].ActorID)
+
+		list, _ = inbox(owner)
+		require.Len(t, list, 1, "users are not told about their own changes")
+		assert.Equal(t, `Mateo completed "Draft budget"`, list[0].Title)
+
+		list, _ = inbox(viewer)
+		assert.Empty(t, list, "other members are not involved in the task")
+	})
+
+	t.Run("should tell a user they were unassigned", func(t *testing.T) {
+		_, err := taskService.UnassignTask(owner, task.ID)
+		require.NoError(t, err)
+		relayAll()
+
+		list, _ := inbox(member)
+		require.Len(t, list, 2)
+		assert.Equal(t, "task.unassigned", list[0].Type)
+	})
+
+	t.Run("should store each event once", func(t *testing.T) {
+		var event models.OutboxEvent
+		require.NoError(t, db.Where("type = ?", "task.unassigned").First(&event).Error)
+		require.NoError(t, notificationService.HandleOutboxEvent(context.Background(), &event))
+		list, _ := inbox(member)
+		assert.Len(t, list, 2)
+	})
+
+	t.Run("should respect muted types", func(t *testing.T) {
+		_, err := notificationService.UpdateSettings(owner, &models.NotificationSettingsRequest{MutedTypes: []string{"task.updated"}})
+		require.NoError(t, err)
+		title := "Draft the budget"
+		_, err = taskService.UpdateTask(member, task.ID, &models.UpdateTaskRequest{Title: &title})
+		require.NoError(t, err)
+		_, err = taskService.MarkTaskAsPending(member, task.ID)
+		require.NoError(t, err)
+		relayAll()
+
+		list, _ := inbox(owner)
+		require.Len(t, list, 2)
+		assert.Equal(t, "task.reopened", list[0].Type)
+	})
+
+	t.Run("should mark notifications read", func(t *testing.T) {
+		list, unread := inbox(owner)
+		assert.Equal(t, int64(2), unread)
+
+		read, err := notificationService.MarkRead(owner, list[0].ID)
+		require.NoError(t, err)
+		require.NotNil(t, read.ReadAt)
+		_, err = notificationService.MarkRead(member, list[0].ID)
+		assert.EqualError(t, err, "notification not found")
+
+		unreadOnly, total, unread, err := notificationService.GetNotifications(owner, &models.NotificationFilter{Unread: true, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, int64(1), unread)
+		assert.Equal(t, list[1].ID, unreadOnly[0].ID)
+
+		marked, err := notificationService.MarkAllRead(owner)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), marked)
+		_, unread = inbox(owner)
+		assert.Zero(t, unread)
+	})
+
+	t.Run("should tell the assignee a task was purged", func(t *testing.T) {
+		purged, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Old plan", ProjectID: &project.ID})
+		require.NoError(t, err)
+		_, err = taskService.AssignTask(owner, purged.ID, viewer)
+		require.NoError(t, err)
+		require.NoError(t, taskService.PurgeTask(owner, purged.ID))
+		relayAll()
+
+		list, _ := inbox(viewer)
+		require.NotEmpty(t, list)
+		assert.Equal(t, "task.deleted", list[0].Type)
+		assert.Equal(t, `Olivia deleted "Old plan"`, list[0].Title)
+		assert.Nil(t, list[0].TaskID)
+	})
+
+	t.Run("should notify overdue tasks once per due date", func(t *testing.T) {
+		past := time.Now().Add(-10 * time.Minute)
+		overdue, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Pay rent", DueDate: &past, ProjectID: &project.ID})
+		require.NoError(t, err)
+		_, err = taskService.AssignTask(owner, overdue.ID, viewer)
+		require.NoError(t, err)
+		// A task that came due while the job was not running still raises its notice
+		longAgo := time.Now().Add(-48 * time.Hour)
+		ancient, err := taskService.CreateTask(owner, &models.CreateTaskRequest{Title: "Ancient", DueDate: &longAgo})
+		require.NoError(t, err)
+
+		added, err := notificationService.NotifyOverdue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), added)
+		added, err = notificationService.NotifyOverdue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, added)
+
+		var notices []models.Notification
+		require.NoError(t, db.Where("type = ?", "task.overdue").Order("id ASC").Find(&notices).Error)
+		require.Len(t, notices, 2)
+		assert.Equal(t, owner, notices[0].UserID)
+		assert.Equal(t, ancient.ID, *notices[0].TaskID)
+		assert.Equal(t, viewer, notices[1].UserID)
+		assert.Nil(t, notices[1].ActorID)
+
+		later := time.Now().Add(-time.Minute)
+		_, err = taskService.UpdateTask(owner, overdue.ID, &models.UpdateTaskRequest{DueDate: &later})
+		require.NoError(t, err)
+		added, err = notificationService.NotifyOverdue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), added)
+	})
+}
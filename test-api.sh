@@ -13,9 +13,22 @@ echo "2. Testing tasks endpoint without authentication (should return 401)..."
 curl -s http://localhost/api/v1/tasks | jq .
 echo ""
 
-# Test creating a JWT token (this would normally be done through an auth service)
-echo "3. For demonstration, you would need a valid JWT token to test the full API"
-echo "   The Go backend is correctly requiring authentication for all protected endpoints."
+# Register a throwaway user and obtain a JWT token
+echo "3. Registering a test user..."
+EMAIL="test-$(date +%s)@example.com"
+TOKEN=$(curl -s -X POST http://localhost/api/v1/auth/register \
+  -H "Content-Type: application/json" \
+  -d "{\"email\":\"$EMAIL\",\"name\":\"API Test\",\"password\":\"password123\"}" | jq -r .token)
+echo "   Token: ${TOKEN:0:20}..."
+echo ""
+
+# Use the token against the protected API
+echo "4. Creating and listing tasks with the token..."
+curl -s -X POST http://localhost/api/v1/tasks \
+  -H "Authorization: Bearer $TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"title":"API smoke test","priority":"high"}' | jq .
+curl -s http://localhost/api/v1/tasks -H "Authorization: Bearer $TOKEN" | jq .
 echo ""
 
 echo "✅ API Integration Test Complete!"
@@ -23,7 +36,7 @@ echo ""
 echo "🎯 Summary:"
 echo "   - Nginx is correctly routing requests"
 echo "   - Go backend is responding on all endpoints"
-echo "   - Authentication middleware is working"
+echo "   - Registration issues tokens and authentication middleware is working"
 echo "   - Frontend is using the correct API URLs"
 echo "   - Database connection is established"
 echo ""